@@ -0,0 +1,109 @@
+package abutil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clfTimeFormat is the timestamp layout of the Common Log Format
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CommonLogMiddleware returns a middleware that writes a line in the NCSA
+// Common Log Format to out for each request, e.g.
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /foo HTTP/1.0" 200 2326
+func CommonLogMiddleware(out io.Writer) func(http.Handler) http.Handler {
+	return accessLogMiddleware(out, false)
+}
+
+// CombinedLogMiddleware is like CommonLogMiddleware, but uses the Combined
+// Log Format, which adds the referrer and user agent
+func CombinedLogMiddleware(out io.Writer) func(http.Handler) http.Handler {
+	return accessLogMiddleware(out, true)
+}
+
+func accessLogMiddleware(out io.Writer, combined bool) func(http.Handler) http.Handler {
+	var m sync.Mutex
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t := time.Now()
+			sw := NewStatusWriter(w)
+			h.ServeHTTP(sw, r)
+
+			line := formatAccessLog(r, sw.Status(), sw.Size(), t, combined)
+
+			m.Lock()
+			io.WriteString(out, line)
+			m.Unlock()
+		})
+	}
+}
+
+// formatAccessLog formats the request in Common or Combined Log Format,
+// including the trailing newline
+func formatAccessLog(r *http.Request, status int, size int64, t time.Time,
+	combined bool) string {
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = escapeLogField(u)
+	}
+
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+
+	sz := "-"
+	if size > 0 {
+		sz = strconv.FormatInt(size, 10)
+	}
+
+	line := fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s`, logHost(r), user,
+		t.Format(clfTimeFormat), escapeLogField(r.Method), escapeLogField(uri),
+		escapeLogField(r.Proto), status, sz)
+
+	if combined {
+		line += fmt.Sprintf(` "%s" "%s"`, escapeLogField(r.Referer()),
+			escapeLogField(r.UserAgent()))
+	}
+
+	return line + "\n"
+}
+
+// logHost returns the first address of RemoteIP, escaped and without spaces,
+// so a spoofed X-Real-IP or X-Forwarded-For header can't add fields to the
+// line
+func logHost(r *http.Request) string {
+	a := strings.TrimSpace(strings.SplitN(RemoteIP(r), ",", 2)[0])
+	if a == "" {
+		return "-"
+	}
+
+	return strings.ReplaceAll(escapeLogField(a), " ", `\x20`)
+}
+
+// escapeLogField escapes quotes, backslashes and non-printable characters
+// the way Apache does
+func escapeLogField(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package abutil
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func accessLogRequest() *http.Request {
+	r, _ := http.NewRequest("GET", "http://some.url/foo?bar=baz", nil)
+	r.RequestURI = "/foo?bar=baz"
+	r.RemoteAddr = "123.456.7.8:1234"
+	r.SetBasicAuth("frank", "secret")
+	r.Header.Set("Referer", "http://other.url/")
+	r.Header.Set("User-Agent", `Some "Agent"`)
+
+	return r
+}
+
+func TestFormatAccessLog(t *testing.T) {
+	r := accessLogRequest()
+	tm := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+
+	out := formatAccessLog(r, http.StatusOK, 2326, tm, false)
+	expected := `123.456.7.8 - frank [10/Oct/2000:13:55:36 -0700] ` +
+		`"GET /foo?bar=baz HTTP/1.1" 200 2326` + "\n"
+	if out != expected {
+		t.Errorf("Expected %q, but got %q", expected, out)
+	}
+
+	out = formatAccessLog(r, http.StatusNotFound, 0, tm, true)
+	expected = `123.456.7.8 - frank [10/Oct/2000:13:55:36 -0700] ` +
+		`"GET /foo?bar=baz HTTP/1.1" 404 - "http://other.url/" ` +
+		`"Some \"Agent\""` + "\n"
+	if out != expected {
+		t.Errorf("Expected %q, but got %q", expected, out)
+	}
+}
+
+func TestFormatAccessLogSpoofedIP(t *testing.T) {
+	tm := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+	expected := `1.2.3.4\x20-\x20admin\x20[01/Jan/2000:00:00:00\x20+0000]\x20\"GET\x20/admin ` +
+		`- frank [10/Oct/2000:13:55:36 -0700] "GET /foo?bar=baz HTTP/1.1" 200 2326` + "\n"
+
+	r := accessLogRequest()
+	r.Header.Set("X-Real-IP", `1.2.3.4 - admin [01/Jan/2000:00:00:00 +0000] "GET /admin`)
+	if out := formatAccessLog(r, http.StatusOK, 2326, tm, false); out != expected {
+		t.Errorf("Expected %q, but got %q", expected, out)
+	}
+
+	r = accessLogRequest()
+	r.Header.Set("X-Forwarded-For", "1.2.3.4, 5.6.7.8")
+	expected = `1.2.3.4 - frank [10/Oct/2000:13:55:36 -0700] ` +
+		`"GET /foo?bar=baz HTTP/1.1" 200 2326` + "\n"
+	if out := formatAccessLog(r, http.StatusOK, 2326, tm, false); out != expected {
+		t.Errorf("Expected %q, but got %q", expected, out)
+	}
+}
+
+func TestEscapeLogField(t *testing.T) {
+	out := escapeLogField("a\"b\\c\nd\x00")
+	expected := `a\"b\\c\x0ad\x00`
+	if out != expected {
+		t.Errorf("Expected %s, but got %s", expected, out)
+	}
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("Foobar"))
+	})
+
+	var buf bytes.Buffer
+	CommonLogMiddleware(&buf)(h).ServeHTTP(httptest.NewRecorder(),
+		accessLogRequest())
+
+	re := regexp.MustCompile(`^123\.456\.7\.8 - frank \[[^\]]+\] ` +
+		`"GET /foo\?bar=baz HTTP/1\.1" 201 6` + "\n$")
+	if !re.MatchString(buf.String()) {
+		t.Errorf("Unexpected common log line %q", buf.String())
+	}
+
+	buf.Reset()
+	CombinedLogMiddleware(&buf)(h).ServeHTTP(httptest.NewRecorder(),
+		accessLogRequest())
+
+	re = regexp.MustCompile(`" 201 6 "http://other\.url/" "Some \\"Agent\\""` +
+		"\n$")
+	if !re.MatchString(buf.String()) {
+		t.Errorf("Unexpected combined log line %q", buf.String())
+	}
+}
@@ -0,0 +1,92 @@
+package abutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// AssetHash returns the path of the file with a short hash of its content
+// inserted before the extension, e.g. "static/app.js" becomes
+// "static/app.3b8f1c2a.js". Prefix a "/" to use it as URL.
+func AssetHash(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return fingerprintName(path, filepath.Ext(path), b), nil
+}
+
+// AssetManifest maps asset names to their fingerprinted names (see
+// AssetHash), e.g. "app.js" to "app.3b8f1c2a.js". Pass it to your templates
+// to reference the fingerprinted URLs, e.g. {{index .Assets "app.js"}}.
+type AssetManifest map[string]string
+
+// NewAssetManifest builds the AssetManifest of all files in fsys. Build it on
+// startup, fsys must not change afterwards.
+func NewAssetManifest(fsys fs.FS) (AssetManifest, error) {
+	m := AssetManifest{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		m[name] = fingerprintName(name, path.Ext(name), b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// AssetHandler returns a handler serving the files of fsys like
+// FileServerFS. Fingerprinted names of the manifest are served as the real
+// file with a Cache-Control of a year, since their content never changes.
+// Other names are served as is, without the SPA fallback. Use
+// http.StripPrefix to mount it below a path like "/static/".
+func AssetHandler(fsys fs.FS, manifest AssetManifest) http.Handler {
+	originals := make(map[string]string, len(manifest))
+	for name, hashed := range manifest {
+		originals[hashed] = name
+	}
+
+	files := FileServerFS(fsys, SPAFallback(""))
+	immutable := FileServerFS(fsys, SPAFallback(""),
+		CacheControl("public, max-age=31536000, immutable"))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+
+		orig, ok := originals[name]
+		if !ok {
+			files.ServeHTTP(w, r)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/" + orig
+		r2.URL.RawPath = ""
+
+		immutable.ServeHTTP(w, r2)
+	})
+}
+
+// fingerprintName inserts the first 32 bits of the SHA-256 of b before ext
+func fingerprintName(name, ext string, b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:4]) + ext
+}
@@ -0,0 +1,91 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+var assetFS = fstest.MapFS{
+	"app.js":        {Data: []byte("console.log(1)")},
+	"css/style.css": {Data: []byte("body {}")},
+	"LICENSE":       {Data: []byte("MIT")},
+}
+
+func TestAssetHash(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "app.js")
+	if err := os.WriteFile(p, []byte("console.log(1)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := AssetHash(p)
+	if expected := p[:len(p)-3] + ".0a286891.js"; err != nil || out != expected {
+		t.Errorf("Expected %s, but got %s (%v)", expected, out, err)
+	}
+
+	if _, err := AssetHash(filepath.Join(t.TempDir(), "missing.js")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestNewAssetManifest(t *testing.T) {
+	m, err := NewAssetManifest(assetFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := AssetManifest{
+		"app.js":        "app.0a286891.js",
+		"css/style.css": "css/style.62368a1a.css",
+		"LICENSE":       "LICENSE.e5dcffe8",
+	}
+	if fmt.Sprint(m) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, but got %v", expected, m)
+	}
+}
+
+func TestAssetHandler(t *testing.T) {
+	m, err := NewAssetManifest(assetFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := AssetHandler(assetFS, m)
+
+	cases := []struct {
+		path, body, cacheControl string
+		code                     int
+	}{
+		{"/" + m["app.js"], "console.log(1)", "public, max-age=31536000, immutable", 200},
+		{"/" + m["css/style.css"], "body {}", "public, max-age=31536000, immutable", 200},
+		{"/app.js", "console.log(1)", "public, max-age=3600", 200},
+		{"/app.00000000.js", "404 page not found\n", "", 404},
+		{"/missing", "404 page not found\n", "", 404},
+	}
+
+	for _, c := range cases {
+		w := fileServerRequest(h, "GET", c.path, nil)
+		if w.Code != c.code || w.Body.String() != c.body {
+			t.Errorf("Expected %d %q for %s, but got %d %q", c.code, c.body, c.path,
+				w.Code, w.Body)
+		}
+
+		if cc := w.Header().Get("Cache-Control"); cc != c.cacheControl {
+			t.Errorf("Expected Cache-Control %q for %s, but got %q", c.cacheControl,
+				c.path, cc)
+		}
+	}
+}
+
+func ExampleAssetHandler() {
+	m, _ := NewAssetManifest(assetFS)
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", AssetHandler(assetFS, m)))
+
+	// In your templates
+	fmt.Println("/static/" + m["app.js"])
+
+	// Output: /static/app.0a286891.js
+}
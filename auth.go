@@ -0,0 +1,106 @@
+package abutil
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// ParseBasicAuth returns the credentials of the Authorization: Basic header.
+// Unlike http.Request.BasicAuth it also accepts base64 without padding. The
+// password may contain colons, only the first one separates it from the user.
+func ParseBasicAuth(r *http.Request) (user, pass string, ok bool) {
+	const prefix = "basic "
+
+	auth := r.Header.Get("Authorization")
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", "", false
+	}
+
+	enc := strings.TrimSpace(auth[len(prefix):])
+	b, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		b, err = base64.RawStdEncoding.DecodeString(enc)
+		if err != nil {
+			return "", "", false
+		}
+	}
+
+	user, pass, ok = strings.Cut(string(b), ":")
+	if !ok {
+		return "", "", false
+	}
+
+	return user, pass, true
+}
+
+// BearerToken returns the token of the Authorization: Bearer header. The
+// scheme is matched case-insensitively and surrounding whitespace is trimmed.
+// ok is false if the header is missing, has another scheme or the token is
+// empty or contains spaces.
+func BearerToken(r *http.Request) (token string, ok bool) {
+	const prefix = "bearer "
+
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	token = strings.TrimSpace(auth[len(prefix):])
+	if token == "" || strings.ContainsAny(token, " \t") {
+		return "", false
+	}
+
+	return token, true
+}
+
+// RequireHeader returns a middleware that responds with 401 if the header is
+// missing and with 403 if validate returns false for its value. Use
+// SecretValidator to check against a secret.
+func RequireHeader(name string, validate func(value string) bool) func(http.Handler) http.Handler {
+	return requireHeader(name, validate, func(w http.ResponseWriter, missing bool) {
+		code := http.StatusForbidden
+		if missing {
+			code = http.StatusUnauthorized
+		}
+
+		http.Error(w, http.StatusText(code), code)
+	})
+}
+
+// RequireHeaderStatus is like RequireHeader, but responds with the given
+// status code and body if the header is missing or invalid
+func RequireHeaderStatus(name string, validate func(value string) bool, code int, body string) func(http.Handler) http.Handler {
+	return requireHeader(name, validate, func(w http.ResponseWriter, _ bool) {
+		http.Error(w, body, code)
+	})
+}
+
+func requireHeader(name string, validate func(string) bool,
+	reject func(w http.ResponseWriter, missing bool)) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v := r.Header.Get(name)
+			if v == "" {
+				reject(w, true)
+				return
+			}
+
+			if !validate(v) {
+				reject(w, false)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SecretValidator returns a validation function, which compares the value to
+// the secret in constant time to prevent timing attacks
+func SecretValidator(secret string) func(value string) bool {
+	return func(v string) bool {
+		return subtle.ConstantTimeCompare([]byte(v), []byte(secret)) == 1
+	}
+}
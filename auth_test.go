@@ -0,0 +1,136 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseBasicAuth(t *testing.T) {
+	cases := []struct {
+		header     string
+		user, pass string
+		ok         bool
+	}{
+		// foo:bar
+		{"Basic Zm9vOmJhcg==", "foo", "bar", true},
+		{"basic Zm9vOmJhcg==", "foo", "bar", true},
+		// foo:bar without padding
+		{"Basic Zm9vOmJhcg", "foo", "bar", true},
+		// foo:b:a:r
+		{"Basic Zm9vOmI6YTpy", "foo", "b:a:r", true},
+		// foo: (empty password)
+		{"Basic Zm9vOg==", "foo", "", true},
+		// foo (no colon)
+		{"Basic Zm9v", "", "", false},
+		{"Basic !!!", "", "", false},
+		{"Bearer Zm9vOmJhcg==", "", "", false},
+		{"Basic", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, c := range cases {
+		mockRequestContext(t, func(r *http.Request) {
+			if c.header != "" {
+				r.Header.Set("Authorization", c.header)
+			}
+
+			user, pass, ok := ParseBasicAuth(r)
+			if user != c.user || pass != c.pass || ok != c.ok {
+				t.Errorf("Expected (%q, %q, %v) for %q, but got (%q, %q, %v)",
+					c.user, c.pass, c.ok, c.header, user, pass, ok)
+			}
+		})
+	}
+}
+
+func ExampleParseBasicAuth() {
+	mockRequestContext(nil, func(r *http.Request) {
+		r.SetBasicAuth("frank", "some:secret")
+
+		user, pass, ok := ParseBasicAuth(r)
+		fmt.Println(user, pass, ok)
+	})
+
+	// Output: frank some:secret true
+}
+
+func TestBearerToken(t *testing.T) {
+	cases := []struct {
+		header, token string
+		ok            bool
+	}{
+		{"Bearer abc.def.ghi", "abc.def.ghi", true},
+		{"bearer abc", "abc", true},
+		{"  BEARER   abc  ", "abc", true},
+		{"Bearer\tabc", "", false},
+		{"Bearer ", "", false},
+		{"Bearer    ", "", false},
+		{"Bearer abc def", "", false},
+		{"Basic Zm9vOmJhcg==", "", false},
+		{"Bearerabc", "", false},
+		{"", "", false},
+	}
+
+	for _, c := range cases {
+		r, _ := http.NewRequest("GET", "http://some.url", nil)
+		r.Header.Set("Authorization", c.header)
+
+		token, ok := BearerToken(r)
+		if token != c.token || ok != c.ok {
+			t.Errorf("Expected (%q, %t) for %q, but got (%q, %t)", c.token, c.ok,
+				c.header, token, ok)
+		}
+	}
+}
+
+func TestRequireHeader(t *testing.T) {
+	h := RequireHeader("X-Api-Key", SecretValidator("secret"))(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("Foobar"))
+		}))
+
+	cases := map[string]int{
+		"":        http.StatusUnauthorized,
+		"wrong":   http.StatusForbidden,
+		"secret2": http.StatusForbidden,
+		"secret":  http.StatusOK,
+	}
+
+	for key, code := range cases {
+		mockRequestContext(t, func(r *http.Request) {
+			if key != "" {
+				r.Header.Set("X-Api-Key", key)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != code {
+				t.Errorf("Expected status %d for %q, but got %d", code, key, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireHeaderStatus(t *testing.T) {
+	h := RequireHeaderStatus("X-Api-Key", SecretValidator("secret"),
+		http.StatusNotFound, "Nothing here")(http.NotFoundHandler())
+
+	for _, key := range []string{"", "wrong"} {
+		mockRequestContext(t, func(r *http.Request) {
+			r.Header.Set("X-Api-Key", key)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusNotFound ||
+				strings.TrimSpace(w.Body.String()) != "Nothing here" {
+				t.Errorf("Expected 404 with Nothing here, but got %d with %q",
+					w.Code, w.Body.String())
+			}
+		})
+	}
+}
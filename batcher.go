@@ -0,0 +1,93 @@
+package abutil
+
+import (
+	"sync"
+	"time"
+)
+
+// Batcher collects items and passes them to a flush function in batches,
+// either once size items are collected or maxDelay after the first item of a
+// batch was added, whichever comes first. It's safe for concurrent use.
+type Batcher[T any] struct {
+	m        sync.Mutex
+	size     int
+	maxDelay time.Duration
+	flush    func([]T)
+
+	items  []T
+	timer  *time.Timer
+	closed bool
+
+	// gen is incremented on each flush, so a timer firing late for an
+	// already flushed batch doesn't flush the next one
+	gen uint64
+}
+
+// NewBatcher creates a new Batcher. The flush function is called with the
+// lock held, so Add blocks while a batch is being flushed.
+func NewBatcher[T any](size int, maxDelay time.Duration, flush func([]T)) *Batcher[T] {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Batcher[T]{size: size, maxDelay: maxDelay, flush: flush}
+}
+
+// Add adds an item to the current batch and flushes it if it's full. It
+// panics if the Batcher is closed.
+func (b *Batcher[T]) Add(item T) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if b.closed {
+		panic("abutil: Add on closed Batcher")
+	}
+
+	b.items = append(b.items, item)
+	if len(b.items) >= b.size {
+		b.flushLocked()
+		return
+	}
+
+	if len(b.items) == 1 {
+		gen := b.gen
+		b.timer = time.AfterFunc(b.maxDelay, func() {
+			b.m.Lock()
+			defer b.m.Unlock()
+
+			if b.gen == gen {
+				b.flushLocked()
+			}
+		})
+	}
+}
+
+// Close flushes the remaining items. Add must not be called afterwards.
+func (b *Batcher[T]) Close() {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	b.flushLocked()
+}
+
+// flushLocked flushes the current batch, the caller must hold the lock
+func (b *Batcher[T]) flushLocked() {
+	b.gen++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	if len(b.items) == 0 {
+		return
+	}
+
+	items := b.items
+	b.items = nil
+	b.flush(items)
+}
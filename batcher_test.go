@@ -0,0 +1,124 @@
+package abutil
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecorder struct {
+	m       sync.Mutex
+	batches [][]int
+}
+
+func (b *batchRecorder) flush(items []int) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.batches = append(b.batches, items)
+}
+
+func (b *batchRecorder) String() string {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	return fmt.Sprint(b.batches)
+}
+
+func TestBatcherSize(t *testing.T) {
+	rec := &batchRecorder{}
+	b := NewBatcher(3, time.Hour, rec.flush)
+
+	for i := 1; i <= 7; i++ {
+		b.Add(i)
+	}
+
+	if out := rec.String(); out != "[[1 2 3] [4 5 6]]" {
+		t.Errorf("Expected [[1 2 3] [4 5 6]], but got %s", out)
+	}
+
+	b.Close()
+	b.Close()
+
+	if out := rec.String(); out != "[[1 2 3] [4 5 6] [7]]" {
+		t.Errorf("Expected [[1 2 3] [4 5 6] [7]], but got %s", out)
+	}
+}
+
+func TestBatcherDelay(t *testing.T) {
+	rec := &batchRecorder{}
+	b := NewBatcher(100, 20*time.Millisecond, rec.flush)
+	defer b.Close()
+
+	b.Add(1)
+	b.Add(2)
+	time.Sleep(50 * time.Millisecond)
+	b.Add(3)
+	time.Sleep(50 * time.Millisecond)
+
+	if out := rec.String(); out != "[[1 2] [3]]" {
+		t.Errorf("Expected [[1 2] [3]], but got %s", out)
+	}
+}
+
+func TestBatcherNoDoubleFlush(t *testing.T) {
+	rec := &batchRecorder{}
+	b := NewBatcher(2, 10*time.Millisecond, rec.flush)
+
+	// Flushed by size, the timer of the first item must not flush [3]
+	b.Add(1)
+	b.Add(2)
+	b.Add(3)
+	time.Sleep(5 * time.Millisecond)
+	b.Add(4)
+	time.Sleep(30 * time.Millisecond)
+
+	if out := rec.String(); out != "[[1 2] [3 4]]" {
+		t.Errorf("Expected [[1 2] [3 4]], but got %s", out)
+	}
+
+	b.Close()
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Add to panic after Close")
+		}
+	}()
+	b.Add(5)
+}
+
+func TestBatcherConcurrent(t *testing.T) {
+	var m sync.Mutex
+	total := 0
+	b := NewBatcher(7, time.Millisecond, func(items []int) {
+		m.Lock()
+		total += len(items)
+		m.Unlock()
+	})
+
+	Parallel(8, func() {
+		for i := 0; i < 100; i++ {
+			b.Add(i)
+		}
+	})
+	b.Close()
+
+	if total != 800 {
+		t.Errorf("Expected 800 flushed items, but got %d", total)
+	}
+}
+
+func ExampleBatcher() {
+	b := NewBatcher(2, time.Second, func(rows []string) {
+		fmt.Println("INSERT", rows)
+	})
+
+	b.Add("foo")
+	b.Add("bar")
+	b.Add("baz")
+	b.Close()
+
+	// Output:
+	// INSERT [foo bar]
+	// INSERT [baz]
+}
@@ -0,0 +1,110 @@
+package abutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// BodyString reads at most maxBytes of the request body, closes it and
+// returns the content with surrounding whitespace trimmed. If the body is
+// larger, an *http.MaxBytesError is returned. A nil body results in "".
+func BodyString(r *http.Request, maxBytes int64) (string, error) {
+	if r.Body == nil {
+		return "", nil
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBytes))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// DrainAndRewind reads the whole request body (at most maxBytes) and
+// replaces it with a reader of the read content, so it can be read again
+// (e.g. by the next handler). It returns the content, nil for a nil body.
+// If the body is larger, an *http.MaxBytesError is returned and the body is
+// left as if it wasn't read, but can't be rewound.
+func DrainAndRewind(r *http.Request, maxBytes int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	// Read one more byte to tell a body of exactly maxBytes from a larger one
+	n := max(maxBytes, 0)
+	if n < math.MaxInt64 {
+		n++
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, n))
+	if err != nil {
+		r.Body.Close()
+		return nil, err
+	}
+
+	if int64(len(b)) > maxBytes {
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+		return nil, &http.MaxBytesError{Limit: maxBytes}
+	}
+	r.Body.Close()
+
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+
+	return b, nil
+}
+
+// readCloser combines a reader with the Close of another one
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// ErrUnsupportedMediaType is returned by DecodeBody for content types or
+// charsets it can't decode, respond with 415 Unsupported Media Type
+var ErrUnsupportedMediaType = errors.New("abutil: unsupported media type")
+
+// ReadJSON decodes the JSON request body (at most maxBytes) into dst and
+// closes it. If the body is larger, an *http.MaxBytesError is returned.
+func ReadJSON(r *http.Request, dst interface{}, maxBytes int64) error {
+	if r.Body == nil {
+		return io.EOF
+	}
+	defer r.Body.Close()
+
+	return json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBytes)).Decode(dst)
+}
+
+// DecodeBody decodes the request body into dst with ReadJSON or DecodeForm,
+// depending on the Content-Type, reading at most maxBytes. Other content
+// types and charsets other than UTF-8 result in ErrUnsupportedMediaType.
+func DecodeBody(r *http.Request, dst interface{}, maxBytes int64) error {
+	mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ErrUnsupportedMediaType
+	}
+
+	if cs, ok := params["charset"]; ok && !strings.EqualFold(cs, "utf-8") &&
+		!strings.EqualFold(cs, "us-ascii") {
+		return ErrUnsupportedMediaType
+	}
+
+	switch {
+	case mt == "application/json", strings.HasSuffix(mt, "+json"):
+		return ReadJSON(r, dst, maxBytes)
+	case mt == "application/x-www-form-urlencoded":
+		return DecodeForm(r, dst, maxBytes)
+	}
+
+	return ErrUnsupportedMediaType
+}
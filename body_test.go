@@ -0,0 +1,171 @@
+package abutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestBodyString(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader("  Foobar\n")}
+	r, _ := http.NewRequest("POST", "http://some.url", nil)
+	r.Body = body
+
+	out, err := BodyString(r, 16)
+	if err != nil || out != "Foobar" {
+		t.Errorf("Expected (Foobar, <nil>), but got (%s, %v)", out, err)
+	}
+
+	if !body.closed {
+		t.Error("Expected the body to be closed")
+	}
+
+	r, _ = http.NewRequest("POST", "http://some.url",
+		strings.NewReader("Some large body"))
+	_, err = BodyString(r, 4)
+
+	var mbe *http.MaxBytesError
+	if !errors.As(err, &mbe) {
+		t.Errorf("Expected an *http.MaxBytesError, but got %v", err)
+	}
+
+	r, _ = http.NewRequest("GET", "http://some.url", nil)
+	out, err = BodyString(r, 4)
+	if err != nil || out != "" {
+		t.Errorf("Expected (\"\", <nil>), but got (%q, %v)", out, err)
+	}
+}
+
+func ExampleBodyString() {
+	r, _ := http.NewRequest("POST", "http://some.url",
+		strings.NewReader("Hello world\n"))
+
+	s, _ := BodyString(r, 1024)
+	fmt.Println(s)
+
+	// Output: Hello world
+}
+
+func TestDrainAndRewind(t *testing.T) {
+	r, _ := http.NewRequest("POST", "http://some.url",
+		strings.NewReader("Foobar"))
+
+	b, err := DrainAndRewind(r, 1<<20)
+	if err != nil || string(b) != "Foobar" {
+		t.Errorf("Expected (Foobar, <nil>), but got (%s, %v)", b, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		b, _ = io.ReadAll(r.Body)
+		if string(b) != "Foobar" {
+			t.Errorf("Expected to read Foobar again, but got %s", b)
+		}
+
+		r.Body, _ = r.GetBody()
+	}
+
+	r, _ = http.NewRequest("GET", "http://some.url", nil)
+	if b, err := DrainAndRewind(r, 1<<20); b != nil || err != nil {
+		t.Errorf("Expected (nil, <nil>), but got (%v, %v)", b, err)
+	}
+
+	rerr := errors.New("Some read error")
+	r.Body = io.NopCloser(iotest.ErrReader(rerr))
+	if _, err := DrainAndRewind(r, 1<<20); err != rerr {
+		t.Errorf("Expected %v, but got %v", rerr, err)
+	}
+
+	r, _ = http.NewRequest("POST", "http://some.url", strings.NewReader("Foobar"))
+	if b, err := DrainAndRewind(r, 6); err != nil || string(b) != "Foobar" {
+		t.Errorf("Expected (Foobar, <nil>) at the limit, but got (%s, %v)", b, err)
+	}
+
+	// A larger body is left readable
+	r, _ = http.NewRequest("POST", "http://some.url", strings.NewReader("Foobar"))
+	var mbe *http.MaxBytesError
+	if _, err := DrainAndRewind(r, 3); !errors.As(err, &mbe) || mbe.Limit != 3 {
+		t.Errorf("Expected an *http.MaxBytesError with limit 3, but got %v", err)
+	}
+
+	if b, _ = io.ReadAll(r.Body); string(b) != "Foobar" {
+		t.Errorf("Expected to read Foobar, but got %s", b)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	var v struct{ Foo string }
+
+	r, _ := http.NewRequest("POST", "http://some.url", strings.NewReader(`{"foo": "bar"}`))
+	if err := ReadJSON(r, &v, 1024); err != nil || v.Foo != "bar" {
+		t.Errorf("Expected (bar, <nil>), but got (%s, %v)", v.Foo, err)
+	}
+
+	r, _ = http.NewRequest("POST", "http://some.url", strings.NewReader(`{"foo": "bar"}`))
+	var mbe *http.MaxBytesError
+	if err := ReadJSON(r, &v, 4); !errors.As(err, &mbe) {
+		t.Errorf("Expected an *http.MaxBytesError, but got %v", err)
+	}
+
+	r, _ = http.NewRequest("POST", "http://some.url", nil)
+	if err := ReadJSON(r, &v, 1024); err != io.EOF {
+		t.Errorf("Expected io.EOF for a nil body, but got %v", err)
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	type user struct {
+		Name string `json:"name" form:"name"`
+	}
+
+	cases := []struct {
+		contentType, body string
+		err               error
+	}{
+		{"application/json", `{"name": "Foo"}`, nil},
+		{"application/json; charset=UTF-8", `{"name": "Foo"}`, nil},
+		{"application/vnd.api+json", `{"name": "Foo"}`, nil},
+		{"application/x-www-form-urlencoded", "name=Foo", nil},
+		{"application/x-www-form-urlencoded; charset=utf-8", "name=Foo", nil},
+		{"application/json; charset=latin1", `{"name": "Foo"}`, ErrUnsupportedMediaType},
+		{"text/plain", "Foo", ErrUnsupportedMediaType},
+		{"", "Foo", ErrUnsupportedMediaType},
+	}
+
+	for _, c := range cases {
+		r, _ := http.NewRequest("POST", "http://some.url", strings.NewReader(c.body))
+		r.Header.Set("Content-Type", c.contentType)
+
+		var u user
+		err := DecodeBody(r, &u, 1<<20)
+		if err != c.err {
+			t.Errorf("Expected %v for %q, but got %v", c.err, c.contentType, err)
+		}
+
+		if c.err == nil && u.Name != "Foo" {
+			t.Errorf("Expected Foo for %q, but got %q", c.contentType, u.Name)
+		}
+	}
+
+	r, _ := http.NewRequest("POST", "http://some.url", strings.NewReader(`{"name": "Foo"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var u user
+	var mbe *http.MaxBytesError
+	if err := DecodeBody(r, &u, 4); !errors.As(err, &mbe) {
+		t.Errorf("Expected an *http.MaxBytesError, but got %v", err)
+	}
+}
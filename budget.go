@@ -0,0 +1,81 @@
+package abutil
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+// ErrBudgetExceeded is returned by Budget.Charge if the budget is exhausted.
+// It's an *HTTPError with status 429, so WriteHTTPError responds with
+// 429 Too Many Requests.
+var ErrBudgetExceeded = NewHTTPError(http.StatusTooManyRequests,
+	"request budget exceeded")
+
+// budgetKey is the context key of the Budget
+var budgetKey = NewContextKey[*Budget]("budget")
+
+// Budget limits the cumulative cost of the work done for a request. It's
+// safe for concurrent use.
+type Budget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// NewBudget creates a new Budget with the given limit
+func NewBudget(limit int) *Budget {
+	return &Budget{limit: int64(limit)}
+}
+
+// Charge adds cost to the used budget. If that exceeds the limit, nothing is
+// charged and ErrBudgetExceeded is returned, so the work shouldn't be done.
+func (b *Budget) Charge(cost int) error {
+	for {
+		used := b.used.Load()
+		if used+int64(cost) > b.limit {
+			return ErrBudgetExceeded
+		}
+
+		if b.used.CompareAndSwap(used, used+int64(cost)) {
+			return nil
+		}
+	}
+}
+
+// Remaining returns the budget left
+func (b *Budget) Remaining() int {
+	return int(b.limit - b.used.Load())
+}
+
+// WithBudget returns a copy of ctx carrying b
+func WithBudget(ctx context.Context, b *Budget) context.Context {
+	return budgetKey.WithValue(ctx, b)
+}
+
+// BudgetFromContext returns the Budget stored by WithBudget and if there was
+// one
+func BudgetFromContext(ctx context.Context) (*Budget, bool) {
+	return budgetKey.Value(ctx)
+}
+
+// ChargeContext charges the Budget of ctx, if any, see Budget.Charge.
+// Without a budget, it returns nil.
+func ChargeContext(ctx context.Context, cost int) error {
+	if b, ok := BudgetFromContext(ctx); ok {
+		return b.Charge(cost)
+	}
+
+	return nil
+}
+
+// RequestBudget is a middleware that adds a new Budget with the given limit
+// to the context of each request. Charge it with ChargeContext and respond
+// to ErrBudgetExceeded with WriteHTTPError.
+func RequestBudget(limit int) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := WithBudget(r.Context(), NewBudget(limit))
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
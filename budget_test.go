@@ -0,0 +1,84 @@
+package abutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(10)
+
+	if err := b.Charge(6); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+
+	if err := b.Charge(5); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded, but got %v", err)
+	}
+
+	// The failed charge isn't counted
+	if r := b.Remaining(); r != 4 {
+		t.Errorf("Expected 4 remaining, but got %d", r)
+	}
+
+	if err := b.Charge(4); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+}
+
+func TestBudgetConcurrent(t *testing.T) {
+	b := NewBudget(100)
+
+	var charged, failed atomic.Int64
+	Parallel(200, func() {
+		if b.Charge(1) == nil {
+			charged.Add(1)
+		} else {
+			failed.Add(1)
+		}
+	})
+
+	if charged.Load() != 100 || failed.Load() != 100 || b.Remaining() != 0 {
+		t.Errorf("Expected 100 charges, but got %d (%d failed, %d remaining)",
+			charged.Load(), failed.Load(), b.Remaining())
+	}
+}
+
+func TestChargeContext(t *testing.T) {
+	if err := ChargeContext(context.Background(), 1000); err != nil {
+		t.Errorf("Expected no error without a budget, but got %v", err)
+	}
+
+	h := RequestBudget(3)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			if err := ChargeContext(r.Context(), 1); err != nil {
+				WriteHTTPError(w, err)
+				return
+			}
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, but got %d", w.Code)
+	}
+}
+
+func ExampleRequestBudget() {
+	h := RequestBudget(10)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ChargeContext(r.Context(), 8)
+
+		b, _ := BudgetFromContext(r.Context())
+		fmt.Println(b.Remaining())
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	// Output: 2
+}
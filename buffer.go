@@ -0,0 +1,102 @@
+package abutil
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+)
+
+// bufferingWriter buffers the response until it exceeds max bytes, then
+// streams it through
+type bufferingWriter struct {
+	http.ResponseWriter
+
+	status    int
+	buf       bytes.Buffer
+	max       int64
+	streaming bool
+}
+
+func (b *bufferingWriter) WriteHeader(code int) {
+	if b.streaming {
+		b.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *bufferingWriter) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.ResponseWriter.Write(p)
+	}
+
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	if b.max > 0 && int64(b.buf.Len()+len(p)) > b.max {
+		if err := b.stream(); err != nil {
+			return 0, err
+		}
+
+		return b.ResponseWriter.Write(p)
+	}
+
+	return b.buf.Write(p)
+}
+
+// stream writes out the buffered response and disables buffering
+func (b *bufferingWriter) stream() error {
+	b.streaming = true
+	b.ResponseWriter.WriteHeader(b.status)
+
+	_, err := b.ResponseWriter.Write(b.buf.Bytes())
+	b.buf = bytes.Buffer{}
+
+	return err
+}
+
+// Flush only flushes once the response is streamed, a buffered response is
+// written after the handler returned
+func (b *bufferingWriter) Flush() {
+	if f, ok := b.ResponseWriter.(http.Flusher); ok && b.streaming {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter
+func (b *bufferingWriter) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
+
+// BufferingMiddleware buffers the whole response and passes it to transform
+// after the handler returned, e.g. to minify it or inject a snippet. The
+// returned status and body are written instead, Content-Length is set
+// accordingly. If maxBytes is > 0 and the body gets larger, the response is
+// streamed through untransformed instead.
+func BufferingMiddleware(transform func(status int, header http.Header, body []byte) (int, []byte), maxBytes int64) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bw := &bufferingWriter{ResponseWriter: w, max: maxBytes}
+			h.ServeHTTP(bw, r)
+
+			if bw.streaming {
+				return
+			}
+
+			status := bw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			status, body := transform(status, w.Header(), bw.buf.Bytes())
+
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(status)
+			w.Write(body)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package abutil
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func bufferingMiddlewareContext(maxBytes int64, fn func(http.ResponseWriter)) (*httptest.ResponseRecorder, bool) {
+	called := false
+	h := BufferingMiddleware(func(status int, header http.Header, body []byte) (int, []byte) {
+		called = true
+		header.Set("X-Transformed", "yes")
+		return http.StatusAccepted, bytes.ToUpper(body)
+	}, maxBytes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fn(w)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	return w, called
+}
+
+func TestBufferingMiddleware(t *testing.T) {
+	w, called := bufferingMiddlewareContext(0, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Length", "11")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello "))
+		w.Write([]byte("world"))
+	})
+
+	if !called || w.Code != http.StatusAccepted || w.Body.String() != "HELLO WORLD" {
+		t.Errorf("Expected the transformed response, but got %d %q", w.Code, w.Body)
+	}
+
+	if w.Header().Get("X-Transformed") != "yes" || w.Header().Get("Content-Length") != "11" {
+		t.Errorf("Expected the transformed headers, but got %v", w.Header())
+	}
+
+	// Empty responses are transformed as well
+	if _, called := bufferingMiddlewareContext(0, func(w http.ResponseWriter) {}); !called {
+		t.Error("Expected transform to be called for an empty response")
+	}
+}
+
+func TestBufferingMiddlewareMaxBytes(t *testing.T) {
+	large := strings.Repeat("a", 100)
+	w, called := bufferingMiddlewareContext(64, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(large[:50]))
+		w.Write([]byte(large[50:]))
+		w.(http.Flusher).Flush()
+	})
+
+	if called || w.Code != http.StatusCreated || w.Body.String() != large {
+		t.Errorf("Expected the untransformed response, but got %d %q", w.Code, w.Body)
+	}
+
+	if !w.Flushed {
+		t.Error("Expected a streamed response to be flushed")
+	}
+
+	w, called = bufferingMiddlewareContext(64, func(w http.ResponseWriter) {
+		w.Write([]byte("small"))
+	})
+
+	if !called || w.Body.String() != "SMALL" {
+		t.Errorf("Expected the transformed response, but got %q", w.Body)
+	}
+}
@@ -0,0 +1,304 @@
+package abutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheOption configures CacheMiddleware
+type CacheOption func(*cacheConfig)
+
+type cacheConfig struct {
+	key   func(*http.Request) string
+	grace time.Duration
+	size  int
+}
+
+// CacheKey sets the function deriving the cache key of a request. The
+// default is the method and URL.
+func CacheKey(fn func(*http.Request) string) CacheOption {
+	return func(c *cacheConfig) {
+		c.key = fn
+	}
+}
+
+// CacheGrace sets how long after the TTL a stale response is still served
+// while it's refreshed in the background. The default is 0, so expired
+// responses aren't served.
+func CacheGrace(d time.Duration) CacheOption {
+	return func(c *cacheConfig) {
+		c.grace = d
+	}
+}
+
+// CacheSize sets the maximum number of cached responses, the least recently
+// used are evicted. The default is 1000.
+func CacheSize(n int) CacheOption {
+	return func(c *cacheConfig) {
+		c.size = n
+	}
+}
+
+// cacheableStatus are the status codes CacheMiddleware stores
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+type cacheEntry struct {
+	resp   *CachedResponse
+	stored time.Time
+
+	// public is set for Cache-Control: public responses, which may be served
+	// to requests with credentials
+	public bool
+}
+
+// cacheFetch is the response of a fetch and the key it's stored under
+type cacheFetch struct {
+	resp *CachedResponse
+	key  string
+
+	// personal is set if the request had credentials and the response
+	// isn't public
+	personal bool
+}
+
+// CacheMiddleware caches responses to GET requests for ttl. After that,
+// they're served stale during the grace period (see CacheGrace) while one
+// request refreshes them in the background. Concurrent misses of a key run
+// the handler only once and share the response, unless it isn't cacheable,
+// then each request is handled on its own. Only responses with a
+// cacheable status and without Set-Cookie or Cache-Control: private or
+// no-store are stored. The request headers named by the Vary header of a
+// response are part of its key. Requests with Authorization or Cookie
+// headers are handled on their own and only get and store responses with
+// Cache-Control: public. The X-Cache header is set to HIT, STALE or MISS.
+func CacheMiddleware(ttl time.Duration, opts ...CacheOption) func(http.Handler) http.Handler {
+	c := cacheConfig{
+		key: func(r *http.Request) string {
+			return r.Method + " " + r.URL.String()
+		},
+		size: 1000,
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+
+	cache := NewLRU[string, cacheEntry](c.size, nil)
+
+	// varies are the Vary headers last seen for a key
+	varies := NewLRU[string, []string](c.size, nil)
+	var flight SingleFlight[*cacheFetch]
+
+	return func(h http.Handler) http.Handler {
+		// run runs the handler and stores the response if it may be. It
+		// returns the key it's stored under.
+		run := func(base string, r *http.Request) (*CachedResponse, string) {
+			rb := &responseBuffer{header: http.Header{}}
+			h.ServeHTTP(rb, r)
+
+			resp := &CachedResponse{
+				Status: rb.Status(),
+				Header: rb.header,
+				Body:   rb.body.Bytes(),
+			}
+
+			vary, ok := varyHeaders(resp)
+			key := cacheKey(base, r, vary)
+			public := isPublic(resp)
+			if ok && isCacheable(resp) && (public || !hasCredentials(r)) {
+				varies.Add(base, vary)
+				cache.Add(key, cacheEntry{resp, time.Now(), public})
+			}
+
+			return resp, key
+		}
+
+		// fetch runs the handler once per key. It returns if this call ran
+		// the handler and the value it panicked with, if any.
+		fetch := func(base, key string, r *http.Request) (f *cacheFetch, leader bool, panicked interface{}) {
+			f, _, _ = flight.Do(key, func() (*cacheFetch, error) {
+				leader = true
+
+				// Recover ourselves to re-panic with the original value
+				defer func() {
+					panicked = recover()
+				}()
+
+				resp, key := run(base, r)
+				return &cacheFetch{resp, key, hasCredentials(r) && !isPublic(resp)}, nil
+			})
+
+			return f, leader, panicked
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "GET" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			base := c.key(r)
+			vary, _ := varies.Get(base)
+			key := cacheKey(base, r, vary)
+			credentials := hasCredentials(r)
+
+			if e, ok := cache.Get(key); ok && (e.public || !credentials) {
+				age := time.Since(e.stored)
+
+				switch {
+				case age < ttl:
+					w.Header().Set("X-Cache", "HIT")
+				case age < ttl+c.grace:
+					w.Header().Set("X-Cache", "STALE")
+
+					// The refresh must outlive this request
+					bg := r.Clone(context.WithoutCancel(r.Context()))
+					go fetch(base, key, bg)
+				default:
+					ok = false
+				}
+
+				if ok {
+					w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+					e.resp.write(w)
+					return
+				}
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+
+			// Responses to requests with credentials may be personal
+			if credentials {
+				resp, _ := run(base, r)
+				resp.write(w)
+				return
+			}
+
+			f, leader, panicked := fetch(base, key, r)
+			switch {
+			case panicked != nil:
+				panic(panicked)
+			case f == nil, !leader && !sharable(f, base, r):
+				// The leader panicked or its response is private to it
+				h.ServeHTTP(w, r)
+			default:
+				f.resp.write(w)
+			}
+		})
+	}
+}
+
+// sharable reports if the response fetched for another request may be
+// served to r, i.e. it's cacheable, not personal and r matches its Vary
+// headers
+func sharable(f *cacheFetch, base string, r *http.Request) bool {
+	vary, ok := varyHeaders(f.resp)
+	return ok && !f.personal && isCacheable(f.resp) &&
+		cacheKey(base, r, vary) == f.key
+}
+
+// hasCredentials reports if the request carries credentials, so the
+// response may be personal
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+}
+
+// varyHeaders returns the sorted, canonical request headers named by the
+// Vary header of resp. It returns false for Vary: *, which is uncacheable.
+func varyHeaders(resp *CachedResponse) ([]string, bool) {
+	var vary []string
+	for _, v := range resp.Header.Values("Vary") {
+		for _, name := range SplitHeaderValues(v) {
+			if name == "*" {
+				return nil, false
+			}
+
+			vary = append(vary, http.CanonicalHeaderKey(name))
+		}
+	}
+	sort.Strings(vary)
+
+	return slices.Compact(vary), true
+}
+
+// cacheKey adds the values of the vary headers of r to the key
+func cacheKey(base string, r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(base)
+
+	// Quote the values, so they can't be shifted
+	for _, name := range vary {
+		fmt.Fprintf(&b, "\n%s: %q", name, r.Header.Values(name))
+	}
+
+	return b.String()
+}
+
+// isPublic reports if the response has Cache-Control: public
+func isPublic(resp *CachedResponse) bool {
+	for _, d := range SplitHeaderValues(resp.Header.Get("Cache-Control")) {
+		if strings.EqualFold(d, "public") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCacheable reports if CacheMiddleware may store the response
+func isCacheable(resp *CachedResponse) bool {
+	if !cacheableStatus[resp.Status] || resp.Header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	cc := strings.ToLower(resp.Header.Get("Cache-Control"))
+	return !strings.Contains(cc, "private") && !strings.Contains(cc, "no-store")
+}
+
+// responseBuffer is an http.ResponseWriter keeping the response in memory
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	return b.body.Write(p)
+}
+
+// Status returns the written status code, 200 if none was written
+func (b *responseBuffer) Status() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+
+	return b.status
+}
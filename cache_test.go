@@ -0,0 +1,280 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func cacheRequest(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+	return w
+}
+
+func TestCacheMiddleware(t *testing.T) {
+	var calls int32
+	h := CacheMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "private")
+		}
+		w.Write([]byte(strconv.Itoa(int(n))))
+	}))
+
+	w := cacheRequest(h, "GET", "/foo")
+	if w.Body.String() != "1" || w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("Expected a miss, but got %q %v", w.Body, w.Header())
+	}
+
+	w = cacheRequest(h, "GET", "/foo")
+	if w.Body.String() != "1" || w.Header().Get("X-Cache") != "HIT" || w.Header().Get("Age") != "0" {
+		t.Errorf("Expected a hit, but got %q %v", w.Body, w.Header())
+	}
+
+	// Other URLs, methods and uncacheable responses
+	if w := cacheRequest(h, "GET", "/foo?bar"); w.Body.String() != "2" {
+		t.Errorf("Expected another URL to miss, but got %q", w.Body)
+	}
+
+	if w := cacheRequest(h, "POST", "/foo"); w.Body.String() != "3" {
+		t.Errorf("Expected POST not to be cached, but got %q", w.Body)
+	}
+
+	cacheRequest(h, "GET", "/private")
+	if w := cacheRequest(h, "GET", "/private"); w.Body.String() != "5" {
+		t.Errorf("Expected private responses not to be cached, but got %q", w.Body)
+	}
+}
+
+func TestCacheMiddlewareStale(t *testing.T) {
+	var calls int32
+	refreshed := make(chan struct{}, 1)
+	h := CacheMiddleware(10*time.Millisecond, CacheGrace(time.Hour))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&calls, 1)
+			w.Write([]byte(strconv.Itoa(int(n))))
+			if n > 1 {
+				refreshed <- struct{}{}
+			}
+		}))
+
+	cacheRequest(h, "GET", "/")
+	time.Sleep(20 * time.Millisecond)
+
+	// The stale response is served right away and refreshed in the background
+	w := cacheRequest(h, "GET", "/")
+	if w.Body.String() != "1" || w.Header().Get("X-Cache") != "STALE" {
+		t.Errorf("Expected the stale response, but got %q %v", w.Body, w.Header())
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a refresh in the background")
+	}
+
+	// Wait for the refreshed response to be stored
+	deadline := time.Now().Add(time.Second)
+	for {
+		w := cacheRequest(h, "GET", "/")
+		if w.Body.String() == "2" && w.Header().Get("X-Cache") == "HIT" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the refreshed response, but got %q %v", w.Body, w.Header())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected one refresh, but got %d calls", n)
+	}
+}
+
+func TestCacheMiddlewareSingleFlight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	h := CacheMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte("slow"))
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w := cacheRequest(h, "GET", "/"); w.Body.String() != "slow" {
+				t.Errorf("Expected the shared response, but got %q", w.Body)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected the handler to run once, but got %d", calls)
+	}
+}
+
+func TestCacheMiddlewareKey(t *testing.T) {
+	var calls int32
+	h := CacheMiddleware(time.Minute, CacheKey(func(r *http.Request) string {
+		return r.URL.Path
+	}), CacheSize(1))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+
+	cacheRequest(h, "GET", "/foo?a")
+	cacheRequest(h, "GET", "/foo?b")
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected the query to be ignored, but got %d calls", calls)
+	}
+
+	// The size is 1, so /bar evicts /foo
+	cacheRequest(h, "GET", "/bar")
+	cacheRequest(h, "GET", "/foo")
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("Expected /foo to be evicted, but got %d calls", calls)
+	}
+}
+
+func TestCacheMiddlewareUncacheableShared(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	h := CacheMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			<-release
+		}
+		w.Header().Set("Set-Cookie", "session="+strconv.Itoa(int(n)))
+	}))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		cookies = map[string]bool{}
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := cacheRequest(h, "GET", "/").Header().Get("Set-Cookie")
+
+			mu.Lock()
+			defer mu.Unlock()
+			if cookies[c] {
+				t.Errorf("Expected %q to be sent to one request only", c)
+			}
+			cookies[c] = true
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 5 {
+		t.Errorf("Expected the handler to run for each request, but got %d", n)
+	}
+}
+
+func TestCacheMiddlewarePanic(t *testing.T) {
+	h := CacheMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("Expected the panic to be re-raised, but got %v", p)
+		}
+	}()
+
+	cacheRequest(h, "GET", "/")
+	t.Error("Expected a panic")
+}
+
+func TestCacheMiddlewareCredentials(t *testing.T) {
+	h := CacheMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+		w.Write([]byte("hello " + r.Header.Get("Authorization")))
+	}))
+
+	request := func(path, auth string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	request("/", "alice")
+	if w := request("/", "bob"); w.Body.String() != "hello bob" {
+		t.Errorf("Expected bob's own response, but got %q", w.Body)
+	}
+
+	if w := request("/", ""); w.Body.String() != "hello " {
+		t.Errorf("Expected an anonymous response, but got %q", w.Body)
+	}
+
+	// Anonymous responses aren't served to requests with credentials
+	if w := request("/", "carol"); w.Body.String() != "hello carol" {
+		t.Errorf("Expected carol's own response, but got %q", w.Body)
+	}
+
+	// Unless they're public
+	request("/public", "alice")
+	if w := request("/public", "bob"); w.Body.String() != "hello alice" ||
+		w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("Expected the cached public response, but got %q %v", w.Body, w.Header())
+	}
+}
+
+func TestCacheMiddlewareVary(t *testing.T) {
+	var calls int32
+	h := CacheMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Vary", "Accept-Encoding")
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+	}))
+
+	request := func(encoding string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", encoding)
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	request("gzip")
+	if w := request("identity"); w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected no gzip response, but got %v", w.Header())
+	}
+
+	if w := request("gzip"); w.Header().Get("X-Cache") != "HIT" ||
+		w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("Expected a cached gzip response, but got %v", w.Header())
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected one call per encoding, but got %d", n)
+	}
+}
@@ -0,0 +1,218 @@
+package abutil
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// maxDrainBytes is the most DrainResponse reads. Draining larger bodies
+// costs more than opening a new connection.
+const maxDrainBytes = 256 << 10
+
+// DrainResponse reads the rest of the response body (up to 256 KiB) and
+// closes it, so the connection can be reused by the http.Client. It's safe to
+// call with a nil response or body.
+func DrainResponse(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	resp.Body.Close()
+}
+
+// ClientOption configures NewHTTPClient
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	timeout         time.Duration
+	dialTimeout     time.Duration
+	maxIdleConns    int
+	maxConnsPerHost int
+	tlsMinVersion   uint16
+	retryAttempts   int
+	retryBackoff    time.Duration
+	retryAllErrors  bool
+}
+
+// WithClientTimeout sets the overall timeout of a request including reading
+// the body, 30 seconds by default
+func WithClientTimeout(d time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.timeout = d
+	}
+}
+
+// WithDialTimeout sets the timeout for establishing connections and TLS
+// handshakes, 5 seconds by default
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.dialTimeout = d
+	}
+}
+
+// WithMaxIdleConns sets the maximum number of idle connections kept in the
+// pool, 100 by default. A tenth of them may be idle per host.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(c *clientConfig) {
+		c.maxIdleConns = n
+	}
+}
+
+// WithMaxConnsPerHost limits the connections per host, 100 by default
+func WithMaxConnsPerHost(n int) ClientOption {
+	return func(c *clientConfig) {
+		c.maxConnsPerHost = n
+	}
+}
+
+// WithTLSMinVersion sets the minimum TLS version, tls.VersionTLS12 by
+// default
+func WithTLSMinVersion(v uint16) ClientOption {
+	return func(c *clientConfig) {
+		c.tlsMinVersion = v
+	}
+}
+
+// WithRetry retries requests with idempotent methods up to attempts times
+// in total with the given initial backoff (see RetryTransient), if they fail
+// transiently (see IsRetryable) or the response status is 429, 502, 503 or
+// 504. Other transport errors, like TLS handshake failures, aren't retried,
+// see WithRetryAllErrors. Requests with a body are only retried if their
+// GetBody is set, as done by http.NewRequest.
+func WithRetry(attempts int, backoff time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.retryAttempts = attempts
+		c.retryBackoff = backoff
+	}
+}
+
+// WithRetryAllErrors makes WithRetry retry all transport errors (see
+// Retry), not only transient ones
+func WithRetryAllErrors() ClientOption {
+	return func(c *clientConfig) {
+		c.retryAllErrors = true
+	}
+}
+
+// NewHTTPClient returns an *http.Client with sane defaults for outbound
+// requests, most importantly a timeout. See the ClientOptions to change them.
+func NewHTTPClient(opts ...ClientOption) *http.Client {
+	c := clientConfig{
+		timeout:         30 * time.Second,
+		dialTimeout:     5 * time.Second,
+		maxIdleConns:    100,
+		maxConnsPerHost: 100,
+		tlsMinVersion:   tls.VersionTLS12,
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+
+	idlePerHost := c.maxIdleConns / 10
+	if idlePerHost < 2 {
+		idlePerHost = 2
+	}
+
+	var rt http.RoundTripper = &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   c.dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       &tls.Config{MinVersion: c.tlsMinVersion},
+		TLSHandshakeTimeout:   c.dialTimeout,
+		MaxIdleConns:          c.maxIdleConns,
+		MaxIdleConnsPerHost:   idlePerHost,
+		MaxConnsPerHost:       c.maxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+
+	if c.retryAttempts > 1 {
+		rt = &retryTransport{
+			next:      rt,
+			attempts:  c.retryAttempts,
+			backoff:   c.retryBackoff,
+			allErrors: c.retryAllErrors,
+		}
+	}
+
+	return &http.Client{
+		Timeout:   c.timeout,
+		Transport: rt,
+	}
+}
+
+// retryTransport retries idempotent requests
+type retryTransport struct {
+	next     http.RoundTripper
+	attempts int
+	backoff  time.Duration
+
+	// allErrors retries all errors instead of only transient ones
+	allErrors bool
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req.Method) ||
+		(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return t.next.RoundTrip(req)
+	}
+
+	retry := RetryTransient
+	if t.allErrors {
+		retry = Retry
+	}
+
+	var resp *http.Response
+	attempt := 0
+	err := retry(req.Context(), t.attempts, t.backoff, func() error {
+		attempt++
+
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		var err error
+		resp, err = t.next.RoundTrip(r)
+		if err != nil {
+			return err
+		}
+
+		// Return the last response as is
+		if RetryableHTTPStatus(resp.StatusCode) && attempt < t.attempts {
+			DrainResponse(resp)
+			return WrapHTTPError(resp.StatusCode, nil)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// isIdempotent reports if requests with the given method can safely be
+// retried
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,162 @@
+package abutil
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDrainResponse(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader("Some body")}
+	DrainResponse(&http.Response{Body: body})
+
+	if n, _ := body.Read(make([]byte, 1)); n != 0 {
+		t.Error("Expected the body to be read completely")
+	}
+
+	if !body.closed {
+		t.Error("Expected the body to be closed")
+	}
+
+	// Large bodies are only drained partially
+	large := &closeRecorder{Reader: strings.NewReader(strings.Repeat("a", maxDrainBytes+10))}
+	DrainResponse(&http.Response{Body: large})
+
+	if rest, _ := io.ReadAll(large); len(rest) != 10 || !large.closed {
+		t.Errorf("Expected 10 bytes left and the body closed, but got %d (%t)",
+			len(rest), large.closed)
+	}
+
+	DrainResponse(nil)
+	DrainResponse(&http.Response{})
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	c := NewHTTPClient()
+	if c.Timeout != 30*time.Second {
+		t.Errorf("Expected a timeout of 30s, but got %s", c.Timeout)
+	}
+
+	tr := c.Transport.(*http.Transport)
+	if tr.MaxIdleConns != 100 || tr.MaxIdleConnsPerHost != 10 || tr.MaxConnsPerHost != 100 {
+		t.Errorf("Expected the default pool limits, but got %d, %d and %d",
+			tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+
+	if tr.TLSClientConfig.MinVersion != tls.VersionTLS12 || tr.TLSHandshakeTimeout != 5*time.Second {
+		t.Errorf("Expected TLS 1.2 and a 5s handshake timeout, but got %x and %s",
+			tr.TLSClientConfig.MinVersion, tr.TLSHandshakeTimeout)
+	}
+
+	c = NewHTTPClient(
+		WithClientTimeout(time.Second),
+		WithDialTimeout(2*time.Second),
+		WithMaxIdleConns(5),
+		WithMaxConnsPerHost(3),
+		WithTLSMinVersion(tls.VersionTLS13),
+	)
+
+	tr = c.Transport.(*http.Transport)
+	if c.Timeout != time.Second || tr.TLSHandshakeTimeout != 2*time.Second ||
+		tr.MaxIdleConns != 5 || tr.MaxIdleConnsPerHost != 2 ||
+		tr.MaxConnsPerHost != 3 || tr.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected the options to be applied, but got %+v", tr)
+	}
+}
+
+func TestNewHTTPClientRetry(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write(b)
+	}))
+	defer s.Close()
+
+	c := NewHTTPClient(WithRetry(3, time.Millisecond))
+
+	req, _ := http.NewRequest("PUT", s.URL, strings.NewReader("body"))
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(b) != "body" || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("Expected 200 \"body\" after 3 calls, but got %d %q after %d",
+			resp.StatusCode, b, atomic.LoadInt32(&calls))
+	}
+
+	// The last response is returned as is
+	atomic.StoreInt32(&calls, -10)
+	resp, err = c.Get(s.URL)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&calls) != -7 {
+		t.Errorf("Expected 503 after 3 calls, but got %v (%v) after %d", resp, err, atomic.LoadInt32(&calls)+10)
+	}
+	DrainResponse(resp)
+
+	// Non-idempotent methods aren't retried
+	atomic.StoreInt32(&calls, 0)
+	resp, err = c.Post(s.URL, "text/plain", strings.NewReader("body"))
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected 503 after 1 call, but got %v (%v) after %d", resp, err, atomic.LoadInt32(&calls))
+	}
+	DrainResponse(resp)
+}
+
+// roundTripFunc is an http.RoundTripper calling itself
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	permanent := errors.New("x509: unknown authority")
+	cases := []struct {
+		err       error
+		allErrors bool
+		calls     int
+	}{
+		{io.ErrUnexpectedEOF, false, 3},
+		{permanent, false, 1},
+		{io.ErrUnexpectedEOF, true, 3},
+		{permanent, true, 3},
+	}
+
+	for _, c := range cases {
+		calls := 0
+		tr := &retryTransport{
+			next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				calls++
+				return nil, c.err
+			}),
+			attempts:  3,
+			backoff:   time.Millisecond,
+			allErrors: c.allErrors,
+		}
+
+		req, _ := http.NewRequest("GET", "http://some.url", nil)
+		if _, err := tr.RoundTrip(req); err != c.err || calls != c.calls {
+			t.Errorf("Expected %v after %d calls (all errors: %t), but got %v after %d",
+				c.err, c.calls, c.allErrors, err, calls)
+		}
+	}
+
+	// The option reaches the transport
+	tr := NewHTTPClient(WithRetry(3, time.Millisecond), WithRetryAllErrors()).Transport
+	if rt, ok := tr.(*retryTransport); !ok || !rt.allErrors {
+		t.Errorf("Expected a retryTransport retrying all errors, but got %#v", tr)
+	}
+}
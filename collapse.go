@@ -0,0 +1,105 @@
+package abutil
+
+import (
+	"net/http"
+	"slices"
+)
+
+// collapseHeaders are part of the fingerprint of collapsed requests, so
+// clients only share responses they'd get anyway
+var collapseHeaders = []string{
+	"Authorization", "Cookie", "Accept", "Accept-Encoding", "Accept-Language",
+}
+
+// collapseMaxBody limits the body read for the fingerprint, requests with a
+// larger one are handled on their own
+const collapseMaxBody = 1 << 20
+
+// collapseWriter records if the response was flushed, which makes it
+// unsharable
+type collapseWriter struct {
+	*teeWriter
+
+	flushed bool
+}
+
+func (c *collapseWriter) Flush() {
+	c.flushed = true
+	c.teeWriter.Flush()
+}
+
+// CollapseMiddleware collapses concurrent identical GET and HEAD requests
+// (see RequestFingerprint, including the Authorization, Cookie and Accept
+// headers): the first one is handled, the others wait for it and get a copy
+// of its response, with the headers set by h, not those set by earlier
+// middleware. If the response sets a cookie or is flushed (e.g. streamed),
+// the waiting requests are handled on their own instead.
+func CollapseMiddleware(h http.Handler) http.Handler {
+	var flight SingleFlight[*CachedResponse]
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := RequestFingerprint(r, collapseMaxBody, collapseHeaders...)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			leader   bool
+			panicked interface{}
+		)
+		resp, _, _ := flight.Do(key, func() (*CachedResponse, error) {
+			leader = true
+
+			// Recover ourselves to re-panic with the original value below
+			defer func() {
+				panicked = recover()
+			}()
+
+			// Headers set before (e.g. a request ID) aren't shared
+			upstream := w.Header().Clone()
+
+			cw := &collapseWriter{teeWriter: &teeWriter{StatusWriter: NewStatusWriter(w)}}
+			h.ServeHTTP(cw, r)
+
+			header := addedHeaders(upstream, w.Header())
+			if cw.flushed || header.Get("Set-Cookie") != "" {
+				return nil, nil
+			}
+
+			return &CachedResponse{
+				Status: cw.Status(),
+				Header: header,
+				Body:   cw.body.Bytes(),
+			}, nil
+		})
+
+		switch {
+		case leader && panicked != nil:
+			panic(panicked)
+		case leader:
+		case resp == nil:
+			h.ServeHTTP(w, r)
+		default:
+			resp.write(w)
+		}
+	})
+}
+
+// addedHeaders returns the headers of after that are missing or different in
+// before
+func addedHeaders(before, after http.Header) http.Header {
+	added := http.Header{}
+	for k, vs := range after {
+		if !slices.Equal(before[k], vs) {
+			added[k] = append([]string(nil), vs...)
+		}
+	}
+
+	return added
+}
@@ -0,0 +1,138 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// collapseRequests fires n concurrent requests and releases the handler once
+// they all arrived
+func collapseRequests(h func(http.ResponseWriter, *http.Request), n int, method string) ([]*httptest.ResponseRecorder, int32) {
+	var calls int32
+	release := make(chan struct{})
+	handler := CollapseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		h(w, r)
+	}))
+
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, n)
+	for i := range recorders {
+		recorders[i] = httptest.NewRecorder()
+
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+
+			handler.ServeHTTP(w, httptest.NewRequest(method, "/expensive", nil))
+		}(recorders[i])
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	return recorders, atomic.LoadInt32(&calls)
+}
+
+func TestCollapseMiddleware(t *testing.T) {
+	recorders, calls := collapseRequests(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Foo", "bar")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("expensive"))
+	}, 10, "GET")
+
+	if calls != 1 {
+		t.Errorf("Expected 1 backend hit, but got %d", calls)
+	}
+
+	for _, w := range recorders {
+		if w.Code != http.StatusAccepted || w.Body.String() != "expensive" || w.Header().Get("X-Foo") != "bar" {
+			t.Errorf("Expected the shared response, but got %d %q %v", w.Code, w.Body, w.Header())
+		}
+	}
+}
+
+func TestCollapseMiddlewareUpstreamHeaders(t *testing.T) {
+	release := make(chan struct{})
+	var ids int32
+	h := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", strconv.Itoa(int(atomic.AddInt32(&ids, 1))))
+			next.ServeHTTP(w, r)
+		})
+	}(CollapseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("X-Foo", "bar")
+	})))
+
+	var wg sync.WaitGroup
+	recorders := make([]*httptest.ResponseRecorder, 5)
+	for i := range recorders {
+		recorders[i] = httptest.NewRecorder()
+
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/expensive", nil))
+		}(recorders[i])
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, w := range recorders {
+		id := w.Header().Get("X-Request-Id")
+		if seen[id] || w.Header().Get("X-Foo") != "bar" {
+			t.Errorf("Expected X-Foo and an own request id, but got %v", w.Header())
+		}
+		seen[id] = true
+	}
+}
+
+func TestCollapseMiddlewareOptOut(t *testing.T) {
+	_, calls := collapseRequests(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		w.Write([]byte("personal"))
+	}, 5, "GET")
+
+	if calls != 5 {
+		t.Errorf("Expected responses with cookies not to be shared, but got %d calls", calls)
+	}
+
+	_, calls = collapseRequests(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("streaming"))
+		w.(http.Flusher).Flush()
+	}, 5, "GET")
+
+	if calls != 5 {
+		t.Errorf("Expected flushed responses not to be shared, but got %d calls", calls)
+	}
+
+	_, calls = collapseRequests(func(w http.ResponseWriter, r *http.Request) {}, 5, "POST")
+	if calls != 5 {
+		t.Errorf("Expected POST requests not to be collapsed, but got %d calls", calls)
+	}
+}
+
+func TestCollapseMiddlewarePanic(t *testing.T) {
+	h := CollapseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected the original panic, but got %v", v)
+		}
+	}()
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -1,9 +1,19 @@
 package abutil
 
 import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"runtime/debug"
 	"sync"
+	"time"
 )
 
+// ErrTimeout is returned by WithTimeout and WithTimeoutResult if the function
+// didn't return in time
+var ErrTimeout = errors.New("abutil: timeout exceeded")
+
 // Parallel runs a given function n times concurrently
 // NOTE: Please set runtime.GOMAXPROCS to runtime.NumCPU() for best
 // performance
@@ -19,3 +29,311 @@ func Parallel(n int, fn func()) {
 		}()
 	}
 }
+
+// WithTimeout runs fn in a goroutine and returns its error, or ErrTimeout if
+// it didn't return within d.
+// NOTE: fn can't be stopped, so the goroutine keeps running after a timeout
+// until fn returns
+func WithTimeout(d time.Duration, fn func() error) error {
+	_, err := WithTimeoutResult(d, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+
+	return err
+}
+
+// WithTimeoutResult is like WithTimeout, but also returns the value of fn.
+// On timeout the zero value of T is returned.
+func WithTimeoutResult[T any](d time.Duration, fn func() (T, error)) (T, error) {
+	type result struct {
+		v   T
+		err error
+	}
+
+	// Buffered, so the goroutine can finish even if nobody is listening
+	c := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		c <- result{v, err}
+	}()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case r := <-c:
+		return r.v, r.err
+	case <-t.C:
+		var zero T
+		return zero, ErrTimeout
+	}
+}
+
+// EveryOption configures Every
+type EveryOption func(*everyConfig)
+
+type everyConfig struct {
+	immediately bool
+	queue       bool
+}
+
+// RunImmediately makes Every run the function right away instead of waiting
+// for the first interval
+func RunImmediately() EveryOption {
+	return func(c *everyConfig) {
+		c.immediately = true
+	}
+}
+
+// QueueOverlapping makes Every queue a run that's due while the previous one
+// is still running, instead of skipping it. The queued run is started right
+// after the previous one returns. At most one run is queued, further ones are
+// skipped, so a slow fn doesn't build up a backlog.
+func QueueOverlapping() EveryOption {
+	return func(c *everyConfig) {
+		c.queue = true
+	}
+}
+
+// Every calls fn every interval until ctx is done, then waits for a running
+// fn to return. Runs never overlap, by default a run that's due while the
+// previous one is still running is skipped (see QueueOverlapping). Panics in
+// fn are recovered and logged, so they don't end the loop. If interval isn't
+// positive, Every returns right away without calling fn.
+func Every(ctx context.Context, interval time.Duration, fn func(context.Context), opts ...EveryOption) {
+	if interval <= 0 {
+		return
+	}
+
+	var c everyConfig
+	for _, o := range opts {
+		o(&c)
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	every(ctx, t.C, fn, c)
+}
+
+// every is Every with the ticks passed in, so tests don't depend on time
+func every(ctx context.Context, ticks <-chan time.Time, fn func(context.Context), c everyConfig) {
+	done := make(chan struct{})
+	running := false
+	queued := false
+
+	start := func() {
+		running = true
+		go func() {
+			defer func() { done <- struct{}{} }()
+			defer func() {
+				if p := recover(); p != nil {
+					log.Printf("abutil: panic in Every: %v\n%s", p, debug.Stack())
+				}
+			}()
+
+			fn(ctx)
+		}()
+	}
+
+	if c.immediately {
+		start()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if running {
+				<-done
+			}
+			return
+		case <-ticks:
+			switch {
+			case ctx.Err() != nil:
+			case !running:
+				start()
+			case c.queue:
+				queued = true
+			}
+		case <-done:
+			running = false
+			if queued && ctx.Err() == nil {
+				queued = false
+				start()
+			}
+		}
+	}
+}
+
+// maxRetryBackoff caps the wait between two attempts of Retry
+const maxRetryBackoff = time.Minute
+
+// Retry calls fn until it returns nil, it was called attempts times or ctx is
+// done, and returns the last error (or ctx.Err()). It waits backoff before
+// the second attempt and doubles the wait after each one, up to a minute.
+// The waits are jittered by ±50%, so clients don't retry in lockstep. fn is
+// called at least once, a negative backoff is treated as 0.
+func Retry(ctx context.Context, attempts int, backoff time.Duration, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff = max(0, min(backoff, maxRetryBackoff))
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			t := time.NewTimer(retryWait(backoff))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-t.C:
+			}
+
+			backoff = min(backoff*2, maxRetryBackoff)
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// retryWait jitters backoff by ±50%, after capping it to maxRetryBackoff
+func retryWait(backoff time.Duration) time.Duration {
+	backoff = max(0, min(backoff, maxRetryBackoff))
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff)+1))
+}
+
+// RetryTransient is like Retry, but only retries transient errors (see
+// IsRetryable). Other errors are returned right away.
+func RetryTransient(ctx context.Context, attempts int, backoff time.Duration, fn func() error) error {
+	var permanent error
+	err := Retry(ctx, attempts, backoff, func() error {
+		err := fn()
+		if err != nil && !IsRetryable(err) {
+			permanent = err
+			return nil
+		}
+
+		return err
+	})
+
+	if permanent != nil {
+		return permanent
+	}
+
+	return err
+}
+
+// Pipeline transforms the values of in with fn, running workers calls
+// concurrently (at least one), and sends the results to the returned channel
+// in the order of in. It's closed once in is closed and all results are sent,
+// after which the error channel receives the first error of fn (or
+// ctx.Err(), or nil). An error cancels the context passed to fn and stops
+// the pipeline. At most about workers results are buffered while waiting for
+// a slow one.
+// Receive from the result channel until it's closed or cancel ctx, otherwise
+// the goroutines leak.
+func Pipeline[T, U any](ctx context.Context, in <-chan T, workers int, fn func(context.Context, T) (U, error)) (<-chan U, <-chan error) {
+	type result struct {
+		v   U
+		err error
+	}
+
+	type job struct {
+		v   T
+		res chan result
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	jobs := make(chan job)
+
+	// The result channels in the order of in, bounding the work in progress
+	order := make(chan chan result, workers)
+
+	go func() {
+		defer close(order)
+		defer close(jobs)
+
+		for {
+			var (
+				v  T
+				ok bool
+			)
+			select {
+			case v, ok = <-in:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+
+			res := make(chan result, 1)
+			select {
+			case order <- res:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- job{v, res}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				v, err := fn(ctx, j.v)
+				j.res <- result{v, err}
+			}
+		}()
+	}
+
+	out := make(chan U)
+	errc := make(chan error, 1)
+	go func() {
+		defer cancel()
+		defer close(errc)
+		defer close(out)
+
+		err := func() error {
+			for res := range order {
+				var r result
+				select {
+				case r = <-res:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+
+				if r.err != nil {
+					return r.err
+				}
+
+				select {
+				case out <- r.v:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return ctx.Err()
+		}()
+
+		errc <- err
+	}()
+
+	return out, errc
+}
@@ -1,9 +1,19 @@
 package abutil
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestParallel(t *testing.T) {
@@ -56,3 +66,416 @@ func ExampleParallel_parameters() {
 
 	Parallel(4, fn("foo", "bar"))
 }
+
+func TestWithTimeout(t *testing.T) {
+	err := WithTimeout(time.Second, func() error {
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+
+	fnErr := errors.New("Some error")
+	err = WithTimeout(time.Second, func() error {
+		return fnErr
+	})
+	if err != fnErr {
+		t.Errorf("Expected %v, but got %v", fnErr, err)
+	}
+
+	err = WithTimeout(10*time.Millisecond, func() error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+	if err != ErrTimeout {
+		t.Errorf("Expected %v, but got %v", ErrTimeout, err)
+	}
+}
+
+func TestWithTimeoutResult(t *testing.T) {
+	v, err := WithTimeoutResult(time.Second, func() (int, error) {
+		return 42, nil
+	})
+	if err != nil || v != 42 {
+		t.Errorf("Expected (42, <nil>), but got (%d, %v)", v, err)
+	}
+
+	v, err = WithTimeoutResult(10*time.Millisecond, func() (int, error) {
+		time.Sleep(100 * time.Millisecond)
+		return 42, nil
+	})
+	if err != ErrTimeout || v != 0 {
+		t.Errorf("Expected (0, %v), but got (%d, %v)", ErrTimeout, v, err)
+	}
+}
+
+func ExampleWithTimeout() {
+	err := WithTimeout(10*time.Millisecond, func() error {
+		// Some third-party call without context support
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	fmt.Println(err)
+
+	// Output: abutil: timeout exceeded
+}
+
+func TestEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	go func() {
+		for {
+			select {
+			case ticks <- time.Now():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var calls int32
+	every(ctx, ticks, func(context.Context) {
+		if atomic.AddInt32(&calls, 1) == 3 {
+			cancel()
+		}
+	}, everyConfig{})
+
+	// Every returned after the context was done, so no more calls happen
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("Expected 3 calls, but got %d", n)
+	}
+}
+
+func TestEveryImmediately(t *testing.T) {
+	var calls int32
+	ctx, cancel := context.WithCancel(context.Background())
+
+	every(ctx, nil, func(context.Context) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+	}, everyConfig{immediately: true})
+
+	if calls != 1 {
+		t.Errorf("Expected 1 call, but got %d", calls)
+	}
+}
+
+func TestEveryOverlap(t *testing.T) {
+	run := func(c everyConfig) int32 {
+		ctx, cancel := context.WithCancel(context.Background())
+		ticks := make(chan time.Time)
+		started := make(chan struct{}, 10)
+		release := make(chan struct{})
+
+		var calls int32
+		returned := make(chan struct{})
+		go func() {
+			every(ctx, ticks, func(ctx context.Context) {
+				atomic.AddInt32(&calls, 1)
+				started <- struct{}{}
+				<-release
+			}, c)
+			close(returned)
+		}()
+
+		// Three ticks are due while the first run is still running
+		ticks <- time.Now()
+		<-started
+		ticks <- time.Now()
+		ticks <- time.Now()
+		ticks <- time.Now()
+		release <- struct{}{}
+
+		if c.queue {
+			// Only one of them was queued
+			<-started
+			release <- struct{}{}
+		}
+
+		cancel()
+		close(release)
+		<-returned
+
+		return atomic.LoadInt32(&calls)
+	}
+
+	if n := run(everyConfig{}); n != 1 {
+		t.Errorf("Expected 1 call when skipping, but got %d", n)
+	}
+
+	if n := run(everyConfig{queue: true}); n != 2 {
+		t.Errorf("Expected 2 calls when queueing, but got %d", n)
+	}
+}
+
+func TestEveryPanic(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time, 1)
+	ticks <- time.Now()
+
+	// The tick is queued or started after the first run, either way there's
+	// a second one
+	var calls int32
+	every(ctx, ticks, func(context.Context) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("Something went wrong")
+		}
+		cancel()
+	}, everyConfig{immediately: true, queue: true})
+
+	if calls != 2 {
+		t.Errorf("Expected the loop to survive panics, but got %d calls", calls)
+	}
+
+	if !strings.Contains(buf.String(), "Something went wrong") {
+		t.Errorf("Expected the panic to be logged, but got %q", buf.String())
+	}
+}
+
+func TestEveryInvalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		called := false
+		Every(context.Background(), interval, func(context.Context) {
+			called = true
+		}, RunImmediately())
+
+		if called {
+			t.Errorf("Expected fn not to be called for an interval of %v", interval)
+		}
+	}
+}
+
+func ExampleEvery() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	Every(ctx, time.Millisecond, func(ctx context.Context) {
+		fmt.Println("Cleaning up")
+		cancel()
+	}, RunImmediately())
+
+	// Output: Cleaning up
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), 5, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary")
+		}
+
+		return nil
+	})
+
+	if err != nil || calls != 3 {
+		t.Errorf("Expected (<nil>, 3 calls), but got (%v, %d calls)", err, calls)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		return fmt.Errorf("attempt %d", calls)
+	})
+
+	if err == nil || err.Error() != "attempt 3" || calls != 3 {
+		t.Errorf("Expected the last error after 3 calls, but got (%v, %d calls)", err, calls)
+	}
+
+	// A negative backoff must not panic
+	calls = 0
+	err = Retry(context.Background(), 2, -time.Second, func() error {
+		calls++
+		return errors.New("temporary")
+	})
+
+	if err == nil || calls != 2 {
+		t.Errorf("Expected an error after 2 calls, but got (%v, %d calls)", err, calls)
+	}
+
+	// fn is called at least once
+	for _, attempts := range []int{0, -1} {
+		calls = 0
+		err = Retry(context.Background(), attempts, time.Millisecond, func() error {
+			calls++
+			return errors.New("temporary")
+		})
+
+		if err == nil || calls != 1 {
+			t.Errorf("Expected an error after 1 call for %d attempts, but got (%v, %d calls)", attempts, err, calls)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = Retry(ctx, 3, time.Hour, func() error {
+		calls++
+		cancel()
+		return errors.New("temporary")
+	})
+
+	if err != context.Canceled || calls != 1 {
+		t.Errorf("Expected (context.Canceled, 1 call), but got (%v, %d calls)", err, calls)
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	calls := 0
+	err := RetryTransient(context.Background(), 5, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return io.ErrUnexpectedEOF
+		}
+
+		return nil
+	})
+
+	if err != nil || calls != 3 {
+		t.Errorf("Expected (<nil>, 3 calls), but got (%v, %d calls)", err, calls)
+	}
+
+	permanent := errors.New("permanent")
+	calls = 0
+	err = RetryTransient(context.Background(), 5, time.Millisecond, func() error {
+		calls++
+		return permanent
+	})
+
+	if err != permanent || calls != 1 {
+		t.Errorf("Expected (permanent, 1 call), but got (%v, %d calls)", err, calls)
+	}
+
+	calls = 0
+	err = RetryTransient(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		return io.ErrUnexpectedEOF
+	})
+
+	if err != io.ErrUnexpectedEOF || calls != 3 {
+		t.Errorf("Expected the last error after 3 calls, but got (%v, %d calls)", err, calls)
+	}
+
+	calls = 0
+	err = RetryTransient(context.Background(), 0, time.Millisecond, func() error {
+		calls++
+		return io.ErrUnexpectedEOF
+	})
+
+	if err != io.ErrUnexpectedEOF || calls != 1 {
+		t.Errorf("Expected (unexpected EOF, 1 call), but got (%v, %d calls)", err, calls)
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	for _, backoff := range []time.Duration{time.Minute, time.Hour, math.MaxInt64} {
+		for i := 0; i < 100; i++ {
+			if w := retryWait(backoff); w < maxRetryBackoff/2 || w > maxRetryBackoff*3/2 {
+				t.Fatalf("Expected a wait between 30s and 1m30s for %v, but got %v", backoff, w)
+			}
+		}
+	}
+
+	if w := retryWait(-time.Second); w != 0 {
+		t.Errorf("Expected no wait for a negative backoff, but got %v", w)
+	}
+}
+
+// pipelineInput returns a closed channel of 0 to n-1, buffered so nothing
+// leaks when a test stops the pipeline early
+func pipelineInput(n int) <-chan int {
+	in := make(chan int, n)
+	for i := 0; i < n; i++ {
+		in <- i
+	}
+	close(in)
+
+	return in
+}
+
+func TestPipeline(t *testing.T) {
+	out, errc := Pipeline(context.Background(), pipelineInput(20), 4,
+		func(ctx context.Context, i int) (string, error) {
+			// Earlier values finish later
+			time.Sleep(time.Duration(20-i) * time.Millisecond / 4)
+			return fmt.Sprint(i), nil
+		})
+
+	var got []string
+	for s := range out {
+		got = append(got, s)
+	}
+
+	if fmt.Sprint(got) != "[0 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19]" {
+		t.Errorf("Expected the results in order, but got %v", got)
+	}
+
+	if err := <-errc; err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+}
+
+func TestPipelineNoWorkers(t *testing.T) {
+	out, errc := Pipeline(context.Background(), pipelineInput(3), 0,
+		func(ctx context.Context, i int) (int, error) {
+			return i * 2, nil
+		})
+
+	var got []int
+	for i := range out {
+		got = append(got, i)
+	}
+
+	if fmt.Sprint(got) != "[0 2 4]" {
+		t.Errorf("Expected one worker to run, but got %v", got)
+	}
+
+	if err := <-errc; err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	testErr := errors.New("test")
+	out, errc := Pipeline(context.Background(), pipelineInput(100), 4,
+		func(ctx context.Context, i int) (int, error) {
+			if i == 5 {
+				return 0, testErr
+			}
+
+			return i, nil
+		})
+
+	var got []int
+	for i := range out {
+		got = append(got, i)
+	}
+
+	if fmt.Sprint(got) != "[0 1 2 3 4]" {
+		t.Errorf("Expected the results before the error, but got %v", got)
+	}
+
+	if err := <-errc; err != testErr {
+		t.Errorf("Expected %v, but got %v", testErr, err)
+	}
+}
+
+func TestPipelineCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errc := Pipeline(ctx, pipelineInput(100), 2,
+		func(ctx context.Context, i int) (int, error) {
+			return i, nil
+		})
+
+	<-out
+	cancel()
+	for range out {
+	}
+
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, but got %v", err)
+	}
+}
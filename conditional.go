@@ -0,0 +1,80 @@
+package abutil
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModified sets the Last-Modified header to modtime and checks it against
+// the If-Modified-Since header of the request. If the resource wasn't
+// modified since, it responds with 304 Not Modified and returns true, so the
+// handler can return early. HTTP dates have second precision, so modtime is
+// truncated to seconds first.
+// A missing or malformed If-Modified-Since or a zero modtime counts as
+// modified. Like in http.ServeContent, the header is only respected for GET
+// and HEAD requests without If-None-Match.
+func NotModified(w http.ResponseWriter, r *http.Request, modtime time.Time) bool {
+	if modtime.IsZero() || modtime.Unix() == 0 {
+		return false
+	}
+
+	modtime = modtime.Truncate(time.Second)
+	w.Header().Set("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+
+	if r.Method != "GET" && r.Method != "HEAD" ||
+		r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modtime.After(since) {
+		return false
+	}
+
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+// CheckIfMatch checks the If-Match header of the request against the
+// current ETag of the resource, to prevent lost updates (e.g. for PUT). It
+// returns (true, 0) if the header is missing, "*" or contains currentETag,
+// and (false, 412) otherwise, so respond with 412 Precondition Failed. The
+// comparison is strong, weak ETags never match. An empty currentETag means
+// the resource doesn't exist, which only a missing header matches.
+func CheckIfMatch(r *http.Request, currentETag string) (proceed bool, status int) {
+	if r.Header.Get("If-Match") == "" {
+		return true, 0
+	}
+
+	return checkIfMatch(r, currentETag)
+}
+
+// RequireIfMatch is like CheckIfMatch, but returns (false, 428) if the
+// header is missing, so respond with 428 Precondition Required
+func RequireIfMatch(r *http.Request, currentETag string) (proceed bool, status int) {
+	if r.Header.Get("If-Match") == "" {
+		return false, http.StatusPreconditionRequired
+	}
+
+	return checkIfMatch(r, currentETag)
+}
+
+func checkIfMatch(r *http.Request, currentETag string) (bool, int) {
+	if currentETag != "" && !strings.HasPrefix(currentETag, "W/") {
+		for _, v := range r.Header.Values("If-Match") {
+			for _, etag := range SplitHeaderValues(v) {
+				if etag == "*" || etag == currentETag {
+					return true, 0
+				}
+			}
+		}
+	}
+
+	return false, http.StatusPreconditionFailed
+}
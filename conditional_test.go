@@ -0,0 +1,112 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	modtime := time.Date(2016, 3, 1, 12, 30, 15, 500000000, time.UTC)
+	lastModified := "Tue, 01 Mar 2016 12:30:15 GMT"
+
+	cases := []struct {
+		method      string
+		header      http.Header
+		notModified bool
+	}{
+		{"GET", http.Header{}, false},
+		{"GET", http.Header{"If-Modified-Since": {lastModified}}, true},
+		{"HEAD", http.Header{"If-Modified-Since": {"Wed, 02 Mar 2016 00:00:00 GMT"}}, true},
+		{"GET", http.Header{"If-Modified-Since": {"Tue, 01 Mar 2016 12:30:14 GMT"}}, false},
+		{"GET", http.Header{"If-Modified-Since": {"yesterday"}}, false},
+		{"POST", http.Header{"If-Modified-Since": {lastModified}}, false},
+		{"GET", http.Header{"If-Modified-Since": {lastModified},
+			"If-None-Match": {`"foo"`}}, false},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, "/", nil)
+		r.Header = c.header
+		w := httptest.NewRecorder()
+
+		if out := NotModified(w, r, modtime); out != c.notModified {
+			t.Errorf("Expected %t for %s %v, but got %t", c.notModified, c.method,
+				c.header, out)
+		}
+
+		if lm := w.Header().Get("Last-Modified"); lm != lastModified {
+			t.Errorf("Expected Last-Modified %s, but got %s", lastModified, lm)
+		}
+
+		if c.notModified && w.Code != http.StatusNotModified {
+			t.Errorf("Expected status 304, but got %d", w.Code)
+		}
+	}
+
+	// Without a modification time, there's nothing to compare
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-Modified-Since", lastModified)
+	w := httptest.NewRecorder()
+	if NotModified(w, r, time.Time{}) || w.Header().Get("Last-Modified") != "" {
+		t.Error("Expected a zero modtime to be modified")
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	etag := `"abc"`
+	cases := []struct {
+		ifMatch  []string
+		etag     string
+		proceed  bool
+		status   int
+		required int
+	}{
+		{nil, etag, true, 0, http.StatusPreconditionRequired},
+		{[]string{`"abc"`}, etag, true, 0, 0},
+		{[]string{"*"}, etag, true, 0, 0},
+		{[]string{`"foo", "abc"`}, etag, true, 0, 0},
+		{[]string{`"foo"`, `"abc"`}, etag, true, 0, 0},
+		{[]string{`"foo"`}, etag, false, http.StatusPreconditionFailed, http.StatusPreconditionFailed},
+		{[]string{`W/"abc"`}, etag, false, http.StatusPreconditionFailed, http.StatusPreconditionFailed},
+		{[]string{`W/"abc"`}, `W/"abc"`, false, http.StatusPreconditionFailed, http.StatusPreconditionFailed},
+		{[]string{"*"}, "", false, http.StatusPreconditionFailed, http.StatusPreconditionFailed},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("PUT", "/", nil)
+		r.Header["If-Match"] = c.ifMatch
+
+		if proceed, status := CheckIfMatch(r, c.etag); proceed != c.proceed || status != c.status {
+			t.Errorf("Expected (%t, %d) for %q and %s, but got (%t, %d)", c.proceed,
+				c.status, c.ifMatch, c.etag, proceed, status)
+		}
+
+		if proceed, status := RequireIfMatch(r, c.etag); proceed != (c.required == 0) || status != c.required {
+			t.Errorf("Expected status %d for %q and %s, but got (%t, %d)", c.required,
+				c.ifMatch, c.etag, proceed, status)
+		}
+	}
+}
+
+func ExampleNotModified() {
+	modtime := time.Date(2016, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if NotModified(w, r, modtime) {
+			return
+		}
+
+		w.Write([]byte("Foobar"))
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-Modified-Since", "Tue, 01 Mar 2016 12:00:00 GMT")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	fmt.Println(w.Code)
+
+	// Output: 304
+}
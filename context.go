@@ -0,0 +1,88 @@
+package abutil
+
+import (
+	"context"
+)
+
+// contextKey is the type of all context keys of this package, so they can't
+// collide with keys of other packages
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+)
+
+// Logger is the logging interface used by this package. *log.Logger
+// implements it and most logging libraries can be adapted easily.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// noopLogger is a Logger that discards everything
+type noopLogger struct{}
+
+func (noopLogger) Printf(string, ...interface{}) {}
+
+// WithLogger returns a copy of ctx carrying the given logger
+func WithLogger(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// LoggerFromContext returns the logger stored by WithLogger and true. If
+// there is none, a no-op logger and false are returned, so the result is
+// never nil.
+func LoggerFromContext(ctx context.Context) (Logger, bool) {
+	if l, ok := ctx.Value(loggerKey).(Logger); ok && l != nil {
+		return l, true
+	}
+
+	return noopLogger{}, false
+}
+
+// ContextKey is a type-safe context key for values of type T. Keys are
+// compared by identity, so two keys never collide, even with the same name.
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey creates a new ContextKey, the name is only used for debugging
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// String returns the name of the key
+func (k *ContextKey[T]) String() string {
+	return "abutil context key " + k.name
+}
+
+// WithValue returns a copy of ctx carrying v
+func (k *ContextKey[T]) WithValue(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// Value returns the value stored in ctx and if there was one
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// KV returns a KV pair of the key and v, to be used with WithValues
+func (k *ContextKey[T]) KV(v T) KV {
+	return KV{key: k, value: v}
+}
+
+// KV is a key value pair for WithValues, created with ContextKey.KV
+type KV struct {
+	key   interface{}
+	value interface{}
+}
+
+// WithValues returns a copy of ctx carrying all given values, which is less
+// noisy than chaining context.WithValue
+func WithValues(ctx context.Context, kvs ...KV) context.Context {
+	for _, kv := range kvs {
+		ctx = context.WithValue(ctx, kv.key, kv.value)
+	}
+
+	return ctx
+}
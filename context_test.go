@@ -0,0 +1,114 @@
+package abutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestLoggerFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.New(&buf, "", 0)
+
+	out, ok := LoggerFromContext(WithLogger(context.Background(), l))
+	if !ok || out != l {
+		t.Errorf("Expected (%v, true), but got (%v, %v)", l, out, ok)
+	}
+
+	out.Printf("Hello %s", "world")
+	if buf.String() != "Hello world\n" {
+		t.Errorf("Expected log output %q, but got %q", "Hello world\n",
+			buf.String())
+	}
+
+	out, ok = LoggerFromContext(context.Background())
+	if ok || out == nil {
+		t.Errorf("Expected a no-op logger and false, but got (%v, %v)", out, ok)
+	}
+
+	// Must not panic
+	out.Printf("Hello %s", "nobody")
+
+	_, ok = LoggerFromContext(WithLogger(context.Background(), nil))
+	if ok {
+		t.Error("Expected false for a nil logger")
+	}
+}
+
+func ExampleLoggerFromContext() {
+	ctx := WithLogger(context.Background(),
+		log.New(os.Stdout, "[req-123] ", 0))
+
+	doWork := func(ctx context.Context) {
+		l, _ := LoggerFromContext(ctx)
+		l.Printf("Doing work")
+	}
+
+	doWork(ctx)
+
+	// Without logger nothing happens
+	doWork(context.Background())
+	fmt.Println("done")
+
+	// Output:
+	// [req-123] Doing work
+	// done
+}
+
+func TestContextKey(t *testing.T) {
+	k := NewContextKey[int]("count")
+	other := NewContextKey[int]("count")
+
+	ctx := k.WithValue(context.Background(), 42)
+	if v, ok := k.Value(ctx); !ok || v != 42 {
+		t.Errorf("Expected (42, true), but got (%d, %v)", v, ok)
+	}
+
+	// Same name, but a different key
+	if v, ok := other.Value(ctx); ok {
+		t.Errorf("Expected no value for another key, but got %d", v)
+	}
+
+	if k.String() != "abutil context key count" {
+		t.Errorf("Unexpected String() %q", k.String())
+	}
+}
+
+func TestWithValues(t *testing.T) {
+	requestID := NewContextKey[string]("request id")
+	userID := NewContextKey[int]("user id")
+
+	ctx := WithValues(context.Background(),
+		requestID.KV("req-123"),
+		userID.KV(42))
+
+	if v, _ := requestID.Value(ctx); v != "req-123" {
+		t.Errorf("Expected request id req-123, but got %q", v)
+	}
+
+	if v, _ := userID.Value(ctx); v != 42 {
+		t.Errorf("Expected user id 42, but got %d", v)
+	}
+
+	if WithValues(context.Background()) != context.Background() {
+		t.Error("Expected the same context without values")
+	}
+}
+
+func ExampleWithValues() {
+	requestID := NewContextKey[string]("request id")
+	userID := NewContextKey[int]("user id")
+
+	ctx := WithValues(context.Background(),
+		requestID.KV("req-123"),
+		userID.KV(42))
+
+	id, _ := requestID.Value(ctx)
+	user, _ := userID.Value(ctx)
+	fmt.Println(id, user)
+
+	// Output: req-123 42
+}
@@ -0,0 +1,73 @@
+package abutil
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Counters is a set of named counters which are safe for concurrent use.
+// Existing counters are incremented atomically under a read lock, so there
+// is little contention. The zero value is ready to use.
+type Counters struct {
+	m        sync.RWMutex
+	counters map[string]*int64
+}
+
+// Inc increments the counter name by one
+func (c *Counters) Inc(name string) {
+	c.Add(name, 1)
+}
+
+// Add adds n to the counter name
+func (c *Counters) Add(name string, n int64) {
+	c.m.RLock()
+	p, ok := c.counters[name]
+	if ok {
+		atomic.AddInt64(p, n)
+	}
+	c.m.RUnlock()
+
+	if ok {
+		return
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.counters == nil {
+		c.counters = make(map[string]*int64)
+	}
+
+	p, ok = c.counters[name]
+	if !ok {
+		p = new(int64)
+		c.counters[name] = p
+	}
+	atomic.AddInt64(p, n)
+}
+
+// Get returns the value of the counter name, 0 if it doesn't exist
+func (c *Counters) Get(name string) int64 {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	if p, ok := c.counters[name]; ok {
+		return atomic.LoadInt64(p)
+	}
+
+	return 0
+}
+
+// Snapshot returns a copy of all counters. It blocks updates while copying,
+// so the values are from a single point in time.
+func (c *Counters) Snapshot() map[string]int64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	s := make(map[string]int64, len(c.counters))
+	for name, p := range c.counters {
+		s[name] = atomic.LoadInt64(p)
+	}
+
+	return s
+}
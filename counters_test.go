@@ -0,0 +1,63 @@
+package abutil
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestCounters(t *testing.T) {
+	var c Counters
+
+	if c.Get("foo") != 0 || len(c.Snapshot()) != 0 {
+		t.Error("Expected a zero value to be empty")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				c.Inc("hits")
+				c.Add("bytes", 2)
+			}
+		}()
+	}
+	wg.Wait()
+
+	expected := map[string]int64{"hits": 1000, "bytes": 2000}
+	s := c.Snapshot()
+	if fmt.Sprint(s) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, but got %v", expected, s)
+	}
+
+	if c.Get("hits") != 1000 {
+		t.Errorf("Expected 1000 hits, but got %d", c.Get("hits"))
+	}
+
+	// The snapshot is a copy
+	s["hits"] = 0
+	c.Add("hits", -1)
+	if c.Get("hits") != 999 {
+		t.Errorf("Expected 999 hits, but got %d", c.Get("hits"))
+	}
+}
+
+func BenchmarkCountersInc(b *testing.B) {
+	var c Counters
+	names := make([]string, 8)
+	for i := range names {
+		names[i] = "counter" + strconv.Itoa(i)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Inc(names[i%len(names)])
+			i++
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package abutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned by DecodeCursor for malformed or tampered
+// cursors
+var ErrInvalidCursor = errors.New("abutil: invalid cursor")
+
+// EncodeCursor returns an opaque pagination cursor of v, which is encoded as
+// JSON and signed with HMAC-SHA256, so clients can't craft their own cursor
+// state. Note that clients can still decode it, don't put secrets in it.
+func EncodeCursor(v interface{}, secret []byte) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(
+		append(payload, hmacSHA256(secret, payload)...)), nil
+}
+
+// DecodeCursor verifies a cursor returned by EncodeCursor and decodes it
+// into dst. Malformed and tampered cursors result in ErrInvalidCursor.
+func DecodeCursor(cursor string, secret []byte, dst interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(b) < sha256.Size {
+		return ErrInvalidCursor
+	}
+
+	payload, sig := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	if !hmac.Equal(sig, hmacSHA256(secret, payload)) {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, dst); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data
+func hmacSHA256(secret, data []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
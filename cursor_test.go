@@ -0,0 +1,57 @@
+package abutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+type cursorState struct {
+	LastID    int    `json:"last_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+func TestCursor(t *testing.T) {
+	secret := []byte("secret")
+	in := cursorState{LastID: 42, CreatedAt: "2016-03-01T12:00:00Z"}
+
+	cursor, err := EncodeCursor(in, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out cursorState
+	if err := DecodeCursor(cursor, secret, &out); err != nil || out != in {
+		t.Errorf("Expected %v, but got %v (%v)", in, out, err)
+	}
+
+	// Tampering with the state invalidates the signature
+	b, _ := base64.RawURLEncoding.DecodeString(cursor)
+	b[len(`{"last_id":`)] = '9'
+	tampered := base64.RawURLEncoding.EncodeToString(b)
+
+	forged, _ := EncodeCursor(cursorState{LastID: 1}, []byte("other secret"))
+
+	for _, c := range []string{tampered, forged, "", "foo", "!!!", cursor[:len(cursor)-2]} {
+		if err := DecodeCursor(c, secret, &out); err != ErrInvalidCursor {
+			t.Errorf("Expected %v for %q, but got %v", ErrInvalidCursor, c, err)
+		}
+	}
+
+	if _, err := EncodeCursor(func() {}, secret); err == nil {
+		t.Error("Expected an error for a value that can't be encoded")
+	}
+}
+
+func ExampleDecodeCursor() {
+	secret := []byte("secret")
+	cursor, _ := EncodeCursor(map[string]int{"last_id": 42}, secret)
+
+	var state struct {
+		LastID int `json:"last_id"`
+	}
+	err := DecodeCursor(cursor, secret, &state)
+	fmt.Println(state.LastID, err)
+
+	// Output: 42 <nil>
+}
@@ -0,0 +1,23 @@
+package abutil
+
+import (
+	"net/http"
+	"time"
+)
+
+// WriteDeadline is a middleware that sets a write deadline of d for the
+// response, so writes to a client that doesn't read fail after d instead of
+// blocking forever. It overrides the WriteTimeout of the http.Server and can
+// be applied per route. net/http resets the deadline once the response is
+// finished, so later requests on the connection aren't affected.
+// Writers wrapped by other middleware need an Unwrap method (see
+// http.ResponseController), otherwise the deadline isn't set.
+func WriteDeadline(d time.Duration) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.NewResponseController(w).SetWriteDeadline(time.Now().Add(d))
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package abutil
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteDeadline(t *testing.T) {
+	errc := make(chan error, 1)
+	s := httptest.NewServer(WriteDeadline(100 * time.Millisecond)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			chunk := make([]byte, 64<<10)
+			start := time.Now()
+
+			// Write until the client's and the kernel's buffers are full
+			for time.Since(start) < 5*time.Second {
+				if _, err := w.Write(chunk); err != nil {
+					errc <- err
+					return
+				}
+			}
+
+			errc <- nil
+		})))
+	defer s.Close()
+
+	// A client that never reads the response
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: %s\r\n\r\n", s.Listener.Addr())
+
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Error("Expected the write deadline to trip")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected the handler to return")
+	}
+}
+
+func TestWriteDeadlineReset(t *testing.T) {
+	s := httptest.NewServer(WriteDeadline(50 * time.Millisecond)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})))
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	// A later request on the same connection isn't affected by the deadline
+	// of the first one
+	for i := 0; i < 2; i++ {
+		fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: %s\r\n\r\n", s.Listener.Addr())
+
+		resp, err := http.ReadResponse(br, nil)
+		if err != nil {
+			t.Fatalf("Expected response %d, but got %v", i, err)
+		}
+		DrainResponse(resp)
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, but got %d", resp.StatusCode)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
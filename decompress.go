@@ -0,0 +1,80 @@
+package abutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DecompressRequest is a middleware that decompresses request bodies with
+// Content-Encoding gzip or deflate and removes the header, so handlers read
+// plain text. The body is decompressed before calling the handler, into
+// memory, so it responds with 400 Bad Request for malformed bodies and with
+// 413 Request Entity Too Large if the decompressed body is larger than
+// maxBytes, which protects against zip bombs. Other encodings result in 415
+// Unsupported Media Type.
+func DecompressRequest(maxBytes int64) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			if enc == "" || enc == "identity" || r.Body == nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			b, err := decompressBody(r.Body, enc, maxBytes)
+			r.Body.Close()
+
+			var mbe *http.MaxBytesError
+			switch {
+			case errors.Is(err, ErrUnsupportedMediaType):
+				http.Error(w, "Unsupported Content-Encoding",
+					http.StatusUnsupportedMediaType)
+				return
+			case errors.As(err, &mbe):
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge),
+					http.StatusRequestEntityTooLarge)
+				return
+			case err != nil:
+				http.Error(w, "Malformed "+enc+" body", http.StatusBadRequest)
+				return
+			}
+
+			r.Header.Del("Content-Encoding")
+			r.Header.Set("Content-Length", strconv.Itoa(len(b)))
+			r.ContentLength = int64(len(b))
+			r.Body = io.NopCloser(bytes.NewReader(b))
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// decompressBody reads and decompresses at most maxBytes of body
+func decompressBody(body io.Reader, enc string, maxBytes int64) ([]byte, error) {
+	var (
+		zr  io.ReadCloser
+		err error
+	)
+
+	switch enc {
+	case "gzip", "x-gzip":
+		zr, err = gzip.NewReader(body)
+	case "deflate":
+		// deflate in HTTP means the zlib format (RFC 9110)
+		zr, err = zlib.NewReader(body)
+	default:
+		return nil, ErrUnsupportedMediaType
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(http.MaxBytesReader(nil, zr, maxBytes))
+}
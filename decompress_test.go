@@ -0,0 +1,90 @@
+package abutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipBytes(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+
+	return buf.Bytes()
+}
+
+func decompressRequest(maxBytes int64, enc string, body []byte) (*httptest.ResponseRecorder, string) {
+	var got string
+	h := DecompressRequest(maxBytes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = r.Header.Get("Content-Encoding") + string(b)
+	}))
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	if enc != "" {
+		r.Header.Set("Content-Encoding", enc)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w, got
+}
+
+func TestDecompressRequest(t *testing.T) {
+	if _, got := decompressRequest(1024, "gzip", gzipBytes("Foobar")); got != "Foobar" {
+		t.Errorf("Expected Foobar, but got %q", got)
+	}
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte("Foobar"))
+	zw.Close()
+	if _, got := decompressRequest(1024, "Deflate", buf.Bytes()); got != "Foobar" {
+		t.Errorf("Expected Foobar, but got %q", got)
+	}
+
+	// Uncompressed bodies are passed through
+	if _, got := decompressRequest(1024, "", []byte("Foobar")); got != "Foobar" {
+		t.Errorf("Expected Foobar, but got %q", got)
+	}
+
+	cases := []struct {
+		enc    string
+		body   []byte
+		status int
+	}{
+		{"gzip", []byte("Foobar"), http.StatusBadRequest},
+		{"gzip", gzipBytes("Foobar")[:15], http.StatusBadRequest},
+		{"gzip", gzipBytes(strings.Repeat("a", 1025)), http.StatusRequestEntityTooLarge},
+		{"br", []byte("Foobar"), http.StatusUnsupportedMediaType},
+	}
+
+	for _, c := range cases {
+		w, got := decompressRequest(1024, c.enc, c.body)
+		if w.Code != c.status || got != "" {
+			t.Errorf("Expected status %d for %s, but got %d (handler read %q)",
+				c.status, c.enc, w.Code, got)
+		}
+	}
+}
+
+func ExampleDecompressRequest() {
+	h := DecompressRequest(1 << 20)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		fmt.Println(string(b))
+	}))
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(gzipBytes("Foobar")))
+	r.Header.Set("Content-Encoding", "gzip")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Output: Foobar
+}
@@ -0,0 +1,135 @@
+package abutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadEnvFile reads KEY=VALUE lines from the given file (e.g. ".env") and sets
+// the variables which aren't in the environment yet, so the real environment
+// wins. Comments (#), "export " prefixes and single or double quoted values
+// are supported.
+func LoadEnvFile(path string) error {
+	return loadEnvFile(path, false)
+}
+
+// LoadEnvFileOverride is like LoadEnvFile, but overwrites existing variables
+func LoadEnvFileOverride(path string) error {
+	return loadEnvFile(path, true)
+}
+
+func loadEnvFile(path string, override bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	vars, err := parseEnv(bufio.NewScanner(f))
+	if err != nil {
+		return fmt.Errorf("abutil: %s: %w", path, err)
+	}
+
+	for _, kv := range vars {
+		if _, ok := os.LookupEnv(kv[0]); ok && !override {
+			continue
+		}
+
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseEnv parses all lines of the scanner into key value pairs
+func parseEnv(s *bufio.Scanner) ([][2]string, error) {
+	var vars [][2]string
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validEnvKey(key) {
+			return nil, fmt.Errorf("line %d: invalid line %q", n, s.Text())
+		}
+
+		value, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		vars = append(vars, [2]string{key, value})
+	}
+
+	return vars, s.Err()
+}
+
+// parseEnvValue unquotes the value or strips trailing comments
+func parseEnvValue(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+
+	switch q := v[0]; q {
+	case '\'', '"':
+		end := -1
+		for i := 1; i < len(v); i++ {
+			if q == '"' && v[i] == '\\' {
+				i++
+				continue
+			}
+
+			if v[i] == q {
+				end = i
+				break
+			}
+		}
+
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quote in %s", v)
+		}
+
+		if rest := strings.TrimSpace(v[end+1:]); rest != "" &&
+			!strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+
+		if q == '\'' {
+			return v[1:end], nil
+		}
+
+		return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`,
+			`\\`, `\`).Replace(v[1:end]), nil
+	}
+
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = v[:i]
+	}
+
+	return strings.TrimSpace(v), nil
+}
+
+func validEnvKey(k string) bool {
+	if k == "" {
+		return false
+	}
+
+	for i, c := range k {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
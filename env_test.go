@@ -0,0 +1,97 @@
+package abutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func envFileContext(t *testing.T, content string, fn func(path string)) {
+	p := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fn(p)
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	content := `# Some comment
+ABUTIL_PLAIN=foo
+export ABUTIL_EXPORTED=bar
+
+ABUTIL_DOUBLE="foo # bar\nbaz \"quoted\""
+ABUTIL_SINGLE='foo\n # bar' # comment
+ABUTIL_COMMENT=foo bar # comment
+ABUTIL_EMPTY=
+ABUTIL_EXISTING=from file
+`
+
+	envFileContext(t, content, func(p string) {
+		t.Setenv("ABUTIL_EXISTING", "from env")
+
+		if err := LoadEnvFile(p); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]string{
+			"ABUTIL_PLAIN":    "foo",
+			"ABUTIL_EXPORTED": "bar",
+			"ABUTIL_DOUBLE":   "foo # bar\nbaz \"quoted\"",
+			"ABUTIL_SINGLE":   `foo\n # bar`,
+			"ABUTIL_COMMENT":  "foo bar",
+			"ABUTIL_EMPTY":    "",
+			"ABUTIL_EXISTING": "from env",
+		}
+
+		for k, v := range expected {
+			out, ok := os.LookupEnv(k)
+			if !ok || out != v {
+				t.Errorf("Expected %s to be %q, but got %q (%v)", k, v, out, ok)
+			}
+
+			// Clean up the variables not registered by t.Setenv
+			if k != "ABUTIL_EXISTING" {
+				os.Unsetenv(k)
+			}
+		}
+	})
+}
+
+func TestLoadEnvFileOverride(t *testing.T) {
+	envFileContext(t, "ABUTIL_EXISTING=from file\n", func(p string) {
+		t.Setenv("ABUTIL_EXISTING", "from env")
+
+		if err := LoadEnvFileOverride(p); err != nil {
+			t.Fatal(err)
+		}
+
+		if out := os.Getenv("ABUTIL_EXISTING"); out != "from file" {
+			t.Errorf("Expected %q, but got %q", "from file", out)
+		}
+	})
+}
+
+func TestLoadEnvFileErrors(t *testing.T) {
+	cases := map[string]string{
+		"FOO=bar\nno equals sign\n": "line 2",
+		"FOO=\"unterminated\n":      "line 1",
+		"1FOO=bar\n":                "line 1",
+		"FOO='bar' baz\n":           "line 1",
+	}
+
+	for content, line := range cases {
+		envFileContext(t, content, func(p string) {
+			err := LoadEnvFile(p)
+			if err == nil || !strings.Contains(err.Error(), line) {
+				t.Errorf("Expected an error on %s for %q, but got %v", line,
+					content, err)
+			}
+		})
+	}
+
+	if err := LoadEnvFile("/does/not/exist"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, but got %v", err)
+	}
+}
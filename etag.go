@@ -0,0 +1,44 @@
+package abutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ETagFromReadSeeker returns a strong ETag (quoted) of the whole content of
+// rs. The content is hashed while reading, so it's not buffered in memory,
+// and rs is rewound to the start afterwards, so it can be served, e.g. with
+// http.ServeContent.
+func ETagFromReadSeeker(rs io.ReadSeeker) (string, error) {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("abutil: seeking to the start: %w", err)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, rs); err != nil {
+		return "", err
+	}
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("abutil: rewinding: %w", err)
+	}
+
+	return formatETag(h), nil
+}
+
+// etagFromBytes returns a strong ETag of b in the format of
+// ETagFromReadSeeker
+func etagFromBytes(b []byte) string {
+	h := sha256.New()
+	h.Write(b)
+
+	return formatETag(h)
+}
+
+// formatETag formats the first 128 bits of the hash as a quoted ETag
+func formatETag(h hash.Hash) string {
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
@@ -0,0 +1,59 @@
+package abutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failingSeeker struct {
+	io.Reader
+}
+
+func (failingSeeker) Seek(int64, int) (int64, error) {
+	return 0, errors.New("not seekable")
+}
+
+func TestETagFromReadSeeker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	content := strings.Repeat("Some large content\n", 1000)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Start somewhere in the middle
+	f.Seek(42, io.SeekStart)
+
+	etag, err := ETagFromReadSeeker(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := etagFromBytes([]byte(content)); etag != expected {
+		t.Errorf("Expected %s, but got %s", expected, etag)
+	}
+
+	// The reader is rewound
+	b, _ := io.ReadAll(f)
+	if string(b) != content {
+		t.Errorf("Expected to read the whole content again, but got %d bytes", len(b))
+	}
+
+	other, _ := ETagFromReadSeeker(strings.NewReader("Other content"))
+	if other == etag || len(other) != 34 {
+		t.Errorf("Expected a different quoted ETag, but got %s", other)
+	}
+
+	if _, err := ETagFromReadSeeker(failingSeeker{strings.NewReader("foo")}); err == nil {
+		t.Error("Expected an error if seeking fails")
+	}
+}
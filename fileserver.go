@@ -0,0 +1,119 @@
+package abutil
+
+import (
+	"bytes"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// FSOption configures FileServerFS
+type FSOption func(*fsConfig)
+
+type fsConfig struct {
+	index        string
+	cacheControl string
+}
+
+// SPAFallback sets the file served for paths without a file extension that
+// don't exist, so client-side routes of single page apps work. The default
+// is "index.html", an empty name disables the fallback.
+func SPAFallback(index string) FSOption {
+	return func(c *fsConfig) {
+		c.index = index
+	}
+}
+
+// CacheControl sets the Cache-Control header for files other than the
+// fallback, which is always revalidated. The default is
+// "public, max-age=3600".
+func CacheControl(value string) FSOption {
+	return func(c *fsConfig) {
+		c.cacheControl = value
+	}
+}
+
+// FileServerFS returns a handler serving the files of fsys (e.g. an
+// embed.FS) with content type, Cache-Control and ETag headers. Missing files
+// and directories result in a 404 instead of a listing, unless the SPA
+// fallback applies (see SPAFallback). fsys must not change, since ETags are
+// cached.
+func FileServerFS(fsys fs.FS, opts ...FSOption) http.Handler {
+	c := fsConfig{
+		index:        "index.html",
+		cacheControl: "public, max-age=3600",
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+
+	var etags SyncMap[string, string]
+
+	serve := func(w http.ResponseWriter, r *http.Request, name string, b []byte, cacheControl string) {
+		etag, ok := etags.Load(name)
+		if !ok {
+			etag = etagFromBytes(b)
+			etags.Store(name, etag)
+		}
+
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", etag)
+
+		// ServeContent handles Content-Type, If-None-Match and ranges
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(b))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+				http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Cleaning the rooted path removes any ..
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = c.index
+		}
+
+		if b, ok := readRegularFile(fsys, name); ok {
+			cacheControl := c.cacheControl
+			if name == c.index {
+				cacheControl = "no-cache"
+			}
+
+			serve(w, r, name, b, cacheControl)
+			return
+		}
+
+		if c.index != "" && path.Ext(name) == "" {
+			if b, ok := readRegularFile(fsys, c.index); ok {
+				serve(w, r, c.index, b, "no-cache")
+				return
+			}
+		}
+
+		http.NotFound(w, r)
+	})
+}
+
+// readRegularFile returns the content of name, if it's a regular file
+func readRegularFile(fsys fs.FS, name string) ([]byte, bool) {
+	if name == "" || !fs.ValidPath(name) {
+		return nil, false
+	}
+
+	if fi, err := fs.Stat(fsys, name); err != nil || !fi.Mode().IsRegular() {
+		return nil, false
+	}
+
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, false
+	}
+
+	return b, true
+}
@@ -0,0 +1,101 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+var fileServerFS = fstest.MapFS{
+	"index.html":    {Data: []byte("<html>index</html>")},
+	"app.js":        {Data: []byte("console.log(1)")},
+	"css/style.css": {Data: []byte("body {}")},
+}
+
+func fileServerRequest(h http.Handler, method, path string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestFileServerFS(t *testing.T) {
+	h := FileServerFS(fileServerFS)
+
+	cases := []struct {
+		path, body, contentType, cacheControl string
+		code                                  int
+	}{
+		{"/app.js", "console.log(1)", "text/javascript; charset=utf-8", "public, max-age=3600", 200},
+		{"/css/style.css", "body {}", "text/css; charset=utf-8", "public, max-age=3600", 200},
+		{"/", "<html>index</html>", "text/html; charset=utf-8", "no-cache", 200},
+		// SPA fallback
+		{"/users/42", "<html>index</html>", "text/html; charset=utf-8", "no-cache", 200},
+		{"/css", "<html>index</html>", "text/html; charset=utf-8", "no-cache", 200},
+		// Missing files
+		{"/missing.js", "404 page not found\n", "text/plain; charset=utf-8", "", 404},
+		{"/../index.html", "<html>index</html>", "text/html; charset=utf-8", "no-cache", 200},
+	}
+
+	for _, c := range cases {
+		w := fileServerRequest(h, "GET", c.path, nil)
+		if w.Code != c.code || w.Body.String() != c.body {
+			t.Errorf("Expected %d %q for %s, but got %d %q", c.code, c.body,
+				c.path, w.Code, w.Body)
+		}
+
+		if ct := w.Header().Get("Content-Type"); ct != c.contentType {
+			t.Errorf("Expected Content-Type %q for %s, but got %q", c.contentType, c.path, ct)
+		}
+
+		if cc := w.Header().Get("Cache-Control"); cc != c.cacheControl {
+			t.Errorf("Expected Cache-Control %q for %s, but got %q", c.cacheControl, c.path, cc)
+		}
+	}
+
+	if w := fileServerRequest(h, "POST", "/app.js", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, but got %d", w.Code)
+	}
+}
+
+func TestFileServerFSETag(t *testing.T) {
+	h := FileServerFS(fileServerFS)
+
+	w := fileServerRequest(h, "GET", "/app.js", nil)
+	etag := w.Header().Get("ETag")
+	if len(etag) != 34 {
+		t.Fatalf("Expected an ETag, but got %q", etag)
+	}
+
+	w = fileServerRequest(h, "GET", "/app.js", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, but got %d", w.Code)
+	}
+
+	if other := fileServerRequest(h, "GET", "/index.html", nil).Header().Get("ETag"); other == etag {
+		t.Error("Expected different ETags for different files")
+	}
+}
+
+func TestFileServerFSOptions(t *testing.T) {
+	h := FileServerFS(fileServerFS, SPAFallback(""), CacheControl("public, max-age=31536000, immutable"))
+
+	if w := fileServerRequest(h, "GET", "/users/42", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without fallback, but got %d", w.Code)
+	}
+
+	if w := fileServerRequest(h, "GET", "/css/", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a directory, but got %d", w.Code)
+	}
+
+	w := fileServerRequest(h, "GET", "/app.js", nil)
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=31536000, immutable" {
+		t.Errorf("Expected the configured Cache-Control, but got %q", cc)
+	}
+}
@@ -0,0 +1,166 @@
+package abutil
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FilterType is the type of a filterable field, see ParseFilters
+type FilterType int
+
+// The supported filter types
+const (
+	FilterString FilterType = iota
+	FilterInt
+	FilterFloat
+	FilterBool
+	FilterTime
+)
+
+// FilterOp is the operator of a Filter
+type FilterOp string
+
+// The supported filter operators. In query parameters they're encoded as
+// suffix of the field name, e.g. age_gte=18, except for FilterEq.
+const (
+	FilterEq  FilterOp = "eq"
+	FilterNe  FilterOp = "ne"
+	FilterGt  FilterOp = "gt"
+	FilterGte FilterOp = "gte"
+	FilterLt  FilterOp = "lt"
+	FilterLte FilterOp = "lte"
+)
+
+var filterOps = []FilterOp{FilterNe, FilterGte, FilterGt, FilterLte, FilterLt}
+
+// Filter is a parsed filter of a list request
+type Filter struct {
+	Field string
+	Op    FilterOp
+
+	// Value is a string, int64, float64, bool or time.Time, depending on
+	// the FilterType of the field
+	Value interface{}
+}
+
+// ParseFilters parses query parameters like status=active&age_gte=18 into
+// Filters, sorted by parameter. Only the fields in allowed are accepted and
+// their values are parsed according to their FilterType (times with
+// ParseTime, bools with ParseBool). Bools only support FilterEq and FilterNe.
+// Unknown fields and invalid values result in an error, except for the
+// parameters in ignored (e.g. "page" or "sort"), which are skipped unless
+// they're a field in allowed.
+func ParseFilters(values url.Values, allowed map[string]FilterType, ignored ...string) ([]Filter, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var filters []Filter
+	for _, key := range keys {
+		field, op := key, FilterEq
+		typ, ok := allowed[field]
+		if !ok && containsString(ignored, key) {
+			continue
+		}
+
+		if !ok {
+			for _, o := range filterOps {
+				if f := strings.TrimSuffix(key, "_"+string(o)); f != key {
+					field, op = f, o
+					typ, ok = allowed[field]
+					break
+				}
+			}
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("abutil: unknown filter field %q", key)
+		}
+
+		if typ == FilterBool && op != FilterEq && op != FilterNe {
+			return nil, fmt.Errorf("abutil: operator %s not supported for %s", op, field)
+		}
+
+		for _, raw := range values[key] {
+			v, err := parseFilterValue(raw, typ)
+			if err != nil {
+				return nil, fmt.Errorf("abutil: invalid value for filter %s: %w", key, err)
+			}
+
+			filters = append(filters, Filter{Field: field, Op: op, Value: v})
+		}
+	}
+
+	return filters, nil
+}
+
+func parseFilterValue(s string, typ FilterType) (interface{}, error) {
+	switch typ {
+	case FilterInt:
+		return strconv.ParseInt(s, 10, 64)
+	case FilterFloat:
+		return strconv.ParseFloat(s, 64)
+	case FilterBool:
+		return ParseBool(s)
+	case FilterTime:
+		return ParseTime(s)
+	}
+
+	return s, nil
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SortField is a field of a sort query parameter, see ParseSort
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses a sort query parameter like "-created_at,name" into
+// SortFields, in order. A leading - sorts the field descending, a leading +
+// ascending (the default). Fields must be in allowed, unknown and empty ones
+// result in an error. An empty value returns no fields.
+func ParseSort(value string, allowed map[string]bool) ([]SortField, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	for _, f := range strings.Split(value, ",") {
+		f = strings.TrimSpace(f)
+
+		var desc bool
+		switch {
+		case strings.HasPrefix(f, "-"):
+			desc, f = true, f[1:]
+		case strings.HasPrefix(f, "+"):
+			f = f[1:]
+		}
+
+		if f == "" {
+			return nil, fmt.Errorf("abutil: empty sort field in %q", value)
+		}
+
+		if !allowed[f] {
+			return nil, fmt.Errorf("abutil: unknown sort field %q", f)
+		}
+
+		fields = append(fields, SortField{Field: f, Desc: desc})
+	}
+
+	return fields, nil
+}
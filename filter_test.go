@@ -0,0 +1,121 @@
+package abutil
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var filterFields = map[string]FilterType{
+	"status":     FilterString,
+	"age":        FilterInt,
+	"score":      FilterFloat,
+	"active":     FilterBool,
+	"created_at": FilterTime,
+}
+
+func TestParseFilters(t *testing.T) {
+	values, _ := url.ParseQuery("status=active&status_ne=banned&age_gte=18&age_lt=65" +
+		"&score_gt=1.5&active=yes&created_at_lte=2016-01-02T03:04:05Z&page=2&per_page=10")
+
+	filters, err := ParseFilters(values, filterFields, "page", "per_page")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Filter{
+		{"active", FilterEq, true},
+		{"age", FilterGte, int64(18)},
+		{"age", FilterLt, int64(65)},
+		{"created_at", FilterLte, time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"score", FilterGt, 1.5},
+		{"status", FilterEq, "active"},
+		{"status", FilterNe, "banned"},
+	}
+
+	if fmt.Sprint(filters) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, but got %v", expected, filters)
+	}
+}
+
+func TestParseFiltersIgnored(t *testing.T) {
+	allowed := map[string]FilterType{"order": FilterInt}
+	values, _ := url.ParseQuery("order=3&page=2")
+
+	filters, err := ParseFilters(values, allowed, "page", "order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Filter{{"order", FilterEq, int64(3)}}
+	if fmt.Sprint(filters) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, but got %v", expected, filters)
+	}
+
+	if _, err := ParseFilters(values, allowed); err == nil {
+		t.Error("Expected an error for page if it isn't ignored")
+	}
+}
+
+func TestParseFiltersErrors(t *testing.T) {
+	invalid := []string{
+		"password=foo",
+		"status_like=act",
+		"age_gte=old",
+		"active_gt=true",
+		"active=maybe",
+		"created_at=yesterday",
+	}
+
+	for _, q := range invalid {
+		values, _ := url.ParseQuery(q)
+		if _, err := ParseFilters(values, filterFields); err == nil {
+			t.Errorf("Expected an error for %s", q)
+		}
+	}
+}
+
+func ExampleParseFilters() {
+	values, _ := url.ParseQuery("status=active&age_gte=18")
+	filters, _ := ParseFilters(values, map[string]FilterType{
+		"status": FilterString,
+		"age":    FilterInt,
+	})
+
+	for _, f := range filters {
+		fmt.Println(f.Field, f.Op, f.Value)
+	}
+
+	// Output:
+	// age gte 18
+	// status eq active
+}
+
+func TestParseSort(t *testing.T) {
+	allowed := map[string]bool{"name": true, "created_at": true}
+
+	cases := []struct {
+		value    string
+		expected []SortField
+	}{
+		{"-created_at,name", []SortField{{"created_at", true}, {"name", false}}},
+		{" +name , -created_at ", []SortField{{"name", false}, {"created_at", true}}},
+		{"name", []SortField{{"name", false}}},
+		{"", nil},
+	}
+
+	for _, c := range cases {
+		fields, err := ParseSort(c.value, allowed)
+		if err != nil || fmt.Sprint(fields) != fmt.Sprint(c.expected) {
+			t.Errorf("Expected (%v, <nil>) for %q, but got (%v, %v)", c.expected,
+				c.value, fields, err)
+		}
+	}
+
+	for _, invalid := range []string{"password", "-name,password", "name,", "-", "Name"} {
+		if _, err := ParseSort(invalid, allowed); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
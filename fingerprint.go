@@ -0,0 +1,42 @@
+package abutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+)
+
+// RequestFingerprint returns a stable hex digest of the request's method,
+// URI, Idempotency-Key and the given headers and body, e.g. to detect
+// retried submissions. The body (at most maxBytes) is rewound (see
+// DrainAndRewind), so handlers can still read it.
+func RequestFingerprint(r *http.Request, maxBytes int64, headers ...string) (string, error) {
+	body, err := DrainAndRewind(r, maxBytes)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+
+	// Prefix each part with its length, so parts can't be shifted
+	write := func(s string) {
+		fmt.Fprintf(h, "%d:%s", len(s), s)
+	}
+
+	write(r.Method)
+	write(r.URL.RequestURI())
+
+	for _, name := range append([]string{"Idempotency-Key"}, headers...) {
+		write(http.CanonicalHeaderKey(name))
+		vals := r.Header.Values(name)
+		fmt.Fprintf(h, "%d:", len(vals))
+		for _, v := range vals {
+			write(v)
+		}
+	}
+
+	write(string(body))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,71 @@
+package abutil
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func fingerprintRequest(t *testing.T, body string, fn func(*http.Request)) string {
+	r, _ := http.NewRequest("POST", "http://some.url/orders?foo=bar",
+		strings.NewReader(body))
+	r.Header.Set("Idempotency-Key", "abc")
+	if fn != nil {
+		fn(r)
+	}
+
+	f, err := RequestFingerprint(r, 1<<20, "X-User")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(r.Body)
+	if string(b) != body {
+		t.Errorf("Expected body %q to be readable, but got %q", body, b)
+	}
+
+	return f
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	f := fingerprintRequest(t, "Foobar", nil)
+
+	if len(f) != 64 {
+		t.Errorf("Expected a hex sha256, but got %s", f)
+	}
+
+	if out := fingerprintRequest(t, "Foobar", nil); out != f {
+		t.Errorf("Expected the same fingerprint %s, but got %s", f, out)
+	}
+
+	// Irrelevant headers don't matter
+	out := fingerprintRequest(t, "Foobar", func(r *http.Request) {
+		r.Header.Set("User-Agent", "Some agent")
+	})
+	if out != f {
+		t.Errorf("Expected the same fingerprint %s, but got %s", f, out)
+	}
+
+	changes := map[string]func(*http.Request){
+		"method": func(r *http.Request) { r.Method = "PUT" },
+		"query":  func(r *http.Request) { r.URL.RawQuery = "foo=baz" },
+		"key":    func(r *http.Request) { r.Header.Set("Idempotency-Key", "def") },
+		"header": func(r *http.Request) { r.Header.Set("X-User", "foo") },
+	}
+
+	for name, fn := range changes {
+		if out := fingerprintRequest(t, "Foobar", fn); out == f {
+			t.Errorf("Expected changed %s to change the fingerprint", name)
+		}
+	}
+
+	if out := fingerprintRequest(t, "Foobaz", nil); out == f {
+		t.Error("Expected a changed body to change the fingerprint")
+	}
+
+	r, _ := http.NewRequest("POST", "http://some.url/orders", strings.NewReader("Foobar"))
+	if _, err := RequestFingerprint(r, 3); err == nil {
+		t.Error("Expected an error for a body larger than maxBytes")
+	}
+}
@@ -0,0 +1,136 @@
+package abutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// DecodeForm parses the query and urlencoded body of r (reading at most
+// maxBytes) and decodes the values into the struct dst points to. Fields are
+// matched by their form tag or their name, a tag of "-" skips the field.
+// Supported are strings, bools (see ParseBool), numbers, time.Duration and
+// slices of these; missing values keep the field untouched.
+func DecodeForm(r *http.Request, dst interface{}, maxBytes int64) error {
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, maxBytes)
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	return decodeValues(r.Form, dst)
+}
+
+// formFieldError is returned by DecodeForm for invalid values
+type formFieldError struct {
+	field string
+	err   error
+}
+
+func (e *formFieldError) Error() string {
+	return fmt.Sprintf("abutil: form field %s: %v", e.field, e.err)
+}
+
+func (e *formFieldError) Unwrap() error {
+	return e.err
+}
+
+// decodeValues decodes url.Values into the struct pointed to by dst
+func decodeValues(values url.Values, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("abutil: form destination must be a pointer to a struct")
+	}
+	v = v.Elem()
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("form"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Slice {
+			s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for j, val := range vals {
+				if err := setFormValue(s.Index(j), val); err != nil {
+					return &formFieldError{name, err}
+				}
+			}
+			fv.Set(s)
+			continue
+		}
+
+		if err := setFormValue(fv, vals[0]); err != nil {
+			return &formFieldError{name, err}
+		}
+	}
+
+	return nil
+}
+
+// setFormValue parses s into v according to its kind
+func setFormValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package abutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type formTestStruct struct {
+	Name    string        `form:"name"`
+	Age     uint8         `form:"age"`
+	Admin   bool          `form:"admin"`
+	Score   float64       `form:"score"`
+	Timeout time.Duration `form:"timeout"`
+	Tags    []string      `form:"tag"`
+	IDs     []int         `form:"id"`
+	Skipped string        `form:"-"`
+	Plain   string
+	private string
+}
+
+func formRequest(query, body string) *http.Request {
+	r := httptest.NewRequest("POST", "/?"+query, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
+
+func TestDecodeForm(t *testing.T) {
+	r := formRequest("tag=a&tag=b",
+		"name=Foo&age=42&admin=yes&score=1.5&timeout=2s&id=1&id=2&Skipped=x&Plain=y&private=z")
+
+	s := formTestStruct{Name: "Default", Skipped: "keep"}
+	if err := DecodeForm(r, &s, 1024); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := formTestStruct{
+		Name:    "Foo",
+		Age:     42,
+		Admin:   true,
+		Score:   1.5,
+		Timeout: 2 * time.Second,
+		Tags:    []string{"a", "b"},
+		IDs:     []int{1, 2},
+		Skipped: "keep",
+		Plain:   "y",
+	}
+	if fmt.Sprint(s) != fmt.Sprint(expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, s)
+	}
+
+	// Missing values keep the field untouched
+	s = formTestStruct{Name: "Default"}
+	if err := DecodeForm(formRequest("", "age=1"), &s, 1024); err != nil || s.Name != "Default" {
+		t.Errorf("Expected Name to be Default, but got %q (%v)", s.Name, err)
+	}
+
+	for _, body := range []string{"age=256", "admin=maybe", "id=1&id=x", "timeout=1"} {
+		if err := DecodeForm(formRequest("", body), &s, 1024); err == nil {
+			t.Errorf("Expected an error for %q", body)
+		}
+	}
+
+	if err := DecodeForm(formRequest("", "name=Foo"), s, 1024); err == nil {
+		t.Error("Expected an error for a non-pointer destination")
+	}
+
+	err := DecodeForm(formRequest("", "name="+strings.Repeat("a", 100)), &s, 16)
+	var mbe *http.MaxBytesError
+	if !errors.As(err, &mbe) {
+		t.Errorf("Expected an *http.MaxBytesError, but got %v", err)
+	}
+}
+
+func ExampleDecodeForm() {
+	r := httptest.NewRequest("POST", "/", strings.NewReader("name=Foo&age=42"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var user struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+	DecodeForm(r, &user, 1024)
+
+	fmt.Printf("%+v", user)
+	// Output: {Name:Foo Age:42}
+}
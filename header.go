@@ -0,0 +1,95 @@
+package abutil
+
+import (
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// JoinHeaderValues trims the values and joins them with ", ", skipping empty
+// ones, as expected for list headers like Vary or Accept
+func JoinHeaderValues(vals []string) string {
+	var parts []string
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			parts = append(parts, v)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// SplitHeaderValues splits a list header value on commas and trims the
+// parts, skipping empty ones. Commas in quoted strings don't split.
+func SplitHeaderValues(s string) []string {
+	var parts []string
+	add := func(p string) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	start := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted:
+			// Skip the escaped character
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			add(s[start:i])
+			start = i + 1
+		}
+	}
+	add(s[start:])
+
+	return parts
+}
+
+// CanonicalizeHeaders rewrites the keys of h to their canonical form (e.g.
+// "content-type" to "Content-Type"), like http.Header.Set does. Values of
+// keys that only differ in case are merged, in the order of the sorted keys.
+func CanonicalizeHeaders(h http.Header) {
+	c := canonicalHeader(h)
+	clear(h)
+
+	for k, vs := range c {
+		h[k] = vs
+	}
+}
+
+// MergeHeaders copies the values of src into dst with canonical keys. If
+// overwrite is true, the values of keys in both replace those in dst,
+// otherwise they're appended. The keys of dst are canonicalized as well, so
+// no key is present twice in different case.
+func MergeHeaders(dst, src http.Header, overwrite bool) {
+	CanonicalizeHeaders(dst)
+
+	for k, vs := range canonicalHeader(src) {
+		if overwrite {
+			dst[k] = vs
+		} else {
+			dst[k] = append(dst[k], vs...)
+		}
+	}
+}
+
+// canonicalHeader returns a copy of h with canonical keys
+func canonicalHeader(h http.Header) http.Header {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	c := make(http.Header, len(h))
+	for _, k := range keys {
+		ck := textproto.CanonicalMIMEHeaderKey(k)
+		c[ck] = append(c[ck], h[k]...)
+	}
+
+	return c
+}
@@ -0,0 +1,107 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestJoinHeaderValues(t *testing.T) {
+	cases := []struct {
+		in       []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"Accept"}, "Accept"},
+		{[]string{" Accept ", "", "Origin", "  "}, "Accept, Origin"},
+	}
+
+	for _, c := range cases {
+		if out := JoinHeaderValues(c.in); out != c.expected {
+			t.Errorf("Expected %q, but got %q", c.expected, out)
+		}
+	}
+}
+
+func TestSplitHeaderValues(t *testing.T) {
+	cases := map[string][]string{
+		"":                     nil,
+		"gzip":                 {"gzip"},
+		" gzip , , deflate ,":  {"gzip", "deflate"},
+		`foo="a, b", bar`:      {`foo="a, b"`, "bar"},
+		`foo="a \", b", bar`:   {`foo="a \", b"`, "bar"},
+		`text/html;q=0.9,*/*`:  {"text/html;q=0.9", "*/*"},
+		`"unterminated, quote`: {`"unterminated, quote`},
+	}
+
+	for in, expected := range cases {
+		out := SplitHeaderValues(in)
+		if fmt.Sprintf("%q", out) != fmt.Sprintf("%q", expected) {
+			t.Errorf("Expected %q for %q, but got %q", expected, in, out)
+		}
+	}
+
+	// Round-trip
+	in := []string{"Accept", `foo="a, b"`, "Origin"}
+	out := SplitHeaderValues(JoinHeaderValues(in))
+	if fmt.Sprint(out) != fmt.Sprint(in) {
+		t.Errorf("Expected %v, but got %v", in, out)
+	}
+}
+
+func ExampleSplitHeaderValues() {
+	fmt.Printf("%q\n", SplitHeaderValues(`gzip, foo="a, b",, br`))
+
+	// Output: ["gzip" "foo=\"a, b\"" "br"]
+}
+
+func TestCanonicalizeHeaders(t *testing.T) {
+	h := http.Header{
+		"content-type": {"text/plain"},
+		"Content-Type": {"text/html"},
+		"x-request-id": {"42"},
+		"Accept":       {"*/*"},
+	}
+	CanonicalizeHeaders(h)
+
+	expected := "map[Accept:[*/*] Content-Type:[text/html text/plain] X-Request-Id:[42]]"
+	if fmt.Sprint(h) != expected {
+		t.Errorf("Expected %s, but got %v", expected, h)
+	}
+}
+
+func TestMergeHeaders(t *testing.T) {
+	cases := []struct {
+		overwrite bool
+		expected  string
+	}{
+		{false, "map[Cache-Control:[no-cache] Content-Type:[text/plain text/html] Vary:[Accept Origin]]"},
+		{true, "map[Cache-Control:[no-cache] Content-Type:[text/html] Vary:[Origin]]"},
+	}
+
+	for _, c := range cases {
+		dst := http.Header{"content-type": {"text/plain"}, "Vary": {"Accept"}}
+		src := http.Header{"Content-Type": {"text/html"}, "vary": {"Origin"},
+			"cache-control": {"no-cache"}}
+		MergeHeaders(dst, src, c.overwrite)
+
+		if fmt.Sprint(dst) != c.expected {
+			t.Errorf("Expected %s with overwrite %t, but got %v", c.expected,
+				c.overwrite, dst)
+		}
+
+		// src isn't modified
+		if len(src["vary"]) != 1 || len(src["Content-Type"]) != 1 {
+			t.Errorf("Expected src to be unchanged, but got %v", src)
+		}
+	}
+}
+
+func ExampleMergeHeaders() {
+	dst := http.Header{"Content-Type": {"text/plain"}}
+	MergeHeaders(dst, http.Header{"content-type": {"application/json"}}, true)
+
+	fmt.Println(dst)
+
+	// Output: map[Content-Type:[application/json]]
+}
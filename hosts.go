@@ -0,0 +1,64 @@
+package abutil
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AllowedHosts returns a middleware that responds with 400 to requests whose
+// host (see RequestHost) isn't one of the given hosts. Hosts are compared
+// case-insensitively without port, a leading wildcard like "*.example.com"
+// matches all subdomains (but not example.com itself).
+func AllowedHosts(hosts ...string) func(http.Handler) http.Handler {
+	return AllowedHostsReject(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusBadRequest),
+				http.StatusBadRequest)
+		}), hosts...)
+}
+
+// AllowedHostsReject is like AllowedHosts, but calls reject for requests
+// with a disallowed host
+func AllowedHostsReject(reject http.Handler, hosts ...string) func(http.Handler) http.Handler {
+	exact := make(map[string]bool)
+	var suffixes []string
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if strings.HasPrefix(h, "*.") {
+			suffixes = append(suffixes, h[1:])
+		} else {
+			exact[h] = true
+		}
+	}
+
+	allowed := func(host string) bool {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+		if exact[host] {
+			return true
+		}
+
+		for _, s := range suffixes {
+			if strings.HasSuffix(host, s) && len(host) > len(s) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !allowed(RequestHost(r)) {
+				reject.ServeHTTP(w, r)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
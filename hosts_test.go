@@ -0,0 +1,64 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	h := AllowedHosts("example.com", "*.example.org")(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("Foobar"))
+		}))
+
+	cases := map[string]int{
+		"example.com":        http.StatusOK,
+		"EXAMPLE.com":        http.StatusOK,
+		"example.com:8080":   http.StatusOK,
+		"www.example.com":    http.StatusBadRequest,
+		"evil.com":           http.StatusBadRequest,
+		"foo.example.org":    http.StatusOK,
+		"a.b.example.org":    http.StatusOK,
+		"foo.example.org:80": http.StatusOK,
+		"example.org":        http.StatusBadRequest,
+		"fooexample.org":     http.StatusBadRequest,
+	}
+
+	for host, code := range cases {
+		r, _ := http.NewRequest("GET", "http://"+host+"/", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != code {
+			t.Errorf("Expected status %d for %s, but got %d", code, host, w.Code)
+		}
+	}
+
+	// Forwarded host
+	r, _ := http.NewRequest("GET", "http://example.com/", nil)
+	r.Header.Set("X-Forwarded-Host", "evil.com")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, but got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAllowedHostsReject(t *testing.T) {
+	reject := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMisdirectedRequest)
+	})
+
+	h := AllowedHostsReject(reject, "example.com")(http.NotFoundHandler())
+
+	r, _ := http.NewRequest("GET", "http://evil.com/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusMisdirectedRequest {
+		t.Errorf("Expected status %d, but got %d", http.StatusMisdirectedRequest,
+			w.Code)
+	}
+}
@@ -1,12 +1,15 @@
 package abutil
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"net"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/tylerb/graceful"
 )
@@ -31,6 +34,41 @@ func RemoteIP(r *http.Request) string {
 	return a
 }
 
+// RequestScheme returns the scheme ("http" or "https") the client used,
+// respecting the X-Forwarded-Proto and Forwarded headers set by proxies
+func RequestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+
+	if p := r.Header.Get("X-Forwarded-Proto"); p != "" {
+		return strings.ToLower(strings.TrimSpace(strings.SplitN(p, ",", 2)[0]))
+	}
+
+	// Forwarded: for=192.0.2.60;proto=https;by=203.0.113.43
+	if f := r.Header.Get("Forwarded"); f != "" {
+		first := strings.SplitN(f, ",", 2)[0]
+		for _, pair := range strings.Split(first, ";") {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) == 2 && strings.EqualFold(kv[0], "proto") {
+				return strings.ToLower(strings.Trim(kv[1], "\""))
+			}
+		}
+	}
+
+	return "http"
+}
+
+// RequestHost returns the host the client requested, respecting the
+// X-Forwarded-Host header set by proxies. The port is kept, if any.
+func RequestHost(r *http.Request) string {
+	if h := r.Header.Get("X-Forwarded-Host"); h != "" {
+		return strings.TrimSpace(strings.SplitN(h, ",", 2)[0])
+	}
+
+	return r.Host
+}
+
 // GracefulServer is basically graceful.Server (github.com/tylerb/graceful),
 // but adds a state variable to check if stopped and doesn't listen on
 // signals (use OnSignal instead)
@@ -42,23 +80,49 @@ type GracefulServer struct {
 
 	// locker controls the access to running
 	locker sync.Locker
+
+	// tasks are the background tasks started with Go
+	tasks *TaskGroup
+
+	// CloseHijacked makes Stop close hijacked connections tracked with
+	// TrackHijacked right away, instead of waiting up to the timeout for
+	// their handlers to finish
+	CloseHijacked bool
+
+	// hijacked are the connections tracked with TrackHijacked
+	hijacked connTracker
+
+	// shutdown is cancelled when Stop is called
+	shutdown       context.Context
+	cancelShutdown context.CancelFunc
+
+	// restartable is set once Serve returned after Stop
+	restartable bool
 }
 
 // NewGracefulServer creates a new GracefulServer with the given handler,
 // which listens on the given port.
 func NewGracefulServer(p int, h http.Handler) *GracefulServer {
+	return NewGracefulServerAddr(":"+strconv.Itoa(p), h)
+}
+
+// NewGracefulServerAddr creates a new GracefulServer with the given handler,
+// which listens on the given address (e.g. "127.0.0.1:1337").
+func NewGracefulServerAddr(addr string, h http.Handler) *GracefulServer {
 	var m sync.Mutex
 	s := &GracefulServer{
 		Server: &graceful.Server{
 			Server: &http.Server{
-				Addr:    ":" + strconv.Itoa(p),
+				Addr:    addr,
 				Handler: h,
 			},
 			NoSignalHandling: true,
 		},
 		stopped: true,
 		locker:  &m,
+		tasks:   NewTaskGroup(),
 	}
+	s.shutdown, s.cancelShutdown = context.WithCancel(context.Background())
 
 	s.Server.ShutdownInitiated = func() { s.setStopped(true) }
 
@@ -79,29 +143,141 @@ func (g *GracefulServer) setStopped(r bool) {
 	g.locker.Unlock()
 }
 
+// Go runs fn as a background task of the server. Its context is cancelled
+// when the server is stopped, see Stop.
+func (g *GracefulServer) Go(fn func(ctx context.Context)) {
+	g.locker.Lock()
+	tasks := g.tasks
+	g.locker.Unlock()
+
+	tasks.Go(fn)
+}
+
+// TrackHijacked tracks a connection hijacked by a handler (e.g. for a
+// WebSocket), which the graceful shutdown doesn't cover otherwise. Stop waits
+// up to its timeout (forever for 0) for release to be called and closes the connection after
+// it (or right away, see CloseHijacked). Call release when the handler is
+// done with the connection:
+//
+//	defer s.TrackHijacked(conn)()
+func (g *GracefulServer) TrackHijacked(c net.Conn) (release func()) {
+	return g.hijacked.add(c)
+}
+
+// ShutdownContext returns a context which is cancelled when Stop is called,
+// so handlers of long-lived connections can say goodbye (e.g. send a
+// WebSocket close frame) and return. Serving a stopped server again starts
+// with a new one.
+func (g *GracefulServer) ShutdownContext() context.Context {
+	g.locker.Lock()
+	defer g.locker.Unlock()
+
+	return g.shutdown
+}
+
+// Stop is equivalent to graceful.Server.Stop, but also cancels all tasks
+// started with Go and the ShutdownContext. Unlike graceful.Server.Stop, it
+// blocks until the tasks returned and the connections tracked with
+// TrackHijacked are released, or the timeout is up. Like TaskGroup.Stop and
+// GracefulListener.Stop, a timeout of 0 waits forever.
+func (g *GracefulServer) Stop(timeout time.Duration) {
+	g.locker.Lock()
+	tasks, cancelShutdown := g.tasks, g.cancelShutdown
+	g.locker.Unlock()
+
+	cancelShutdown()
+	g.Server.Stop(timeout)
+
+	if g.CloseHijacked {
+		g.hijacked.closeAll()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		g.hijacked.wait(timeout)
+		close(done)
+	}()
+
+	tasks.Stop(timeout)
+	<-done
+}
+
+// serve marks the server as running while fn serves. A server that was
+// stopped after serving gets a new shutdown context and task group, as the
+// old ones are cancelled.
+func (g *GracefulServer) serve(fn func() error) error {
+	g.locker.Lock()
+	g.stopped = false
+	if g.restartable {
+		g.restartable = false
+		g.shutdown, g.cancelShutdown = context.WithCancel(context.Background())
+		g.tasks = NewTaskGroup()
+	}
+	g.locker.Unlock()
+
+	err := fn()
+
+	g.locker.Lock()
+	g.restartable = g.shutdown.Err() != nil
+	g.locker.Unlock()
+
+	return err
+}
+
 // Serve is equivalent to http.Server.Serve with graceful shutdown enabled
 func (g *GracefulServer) Serve(l net.Listener) error {
-	g.setStopped(false)
-	return g.Server.Serve(l)
+	return g.serve(func() error {
+		return g.Server.Serve(l)
+	})
 }
 
 // ListenAndServe is equivalent to http.Server.ListenAndServe with graceful
 // shutdown enabled
 func (g *GracefulServer) ListenAndServe() error {
-	g.setStopped(false)
-	return g.Server.ListenAndServe()
+	return g.serve(g.Server.ListenAndServe)
 }
 
 // ListenAndServeTLS is equivalent to http.Server.ListenAndServeTLS with
 // graceful shutdown enabled
 func (g *GracefulServer) ListenAndServeTLS(cf, kf string) error {
-	g.setStopped(false)
-	return g.Server.ListenAndServeTLS(cf, kf)
+	return g.serve(func() error {
+		return g.Server.ListenAndServeTLS(cf, kf)
+	})
 }
 
 // ListenAndServeTLSConfig is equivalent to
 // http.Server.ListenAndServeTLSConfig with graceful shutdown enabled
 func (g *GracefulServer) ListenAndServeTLSConfig(c *tls.Config) error {
-	g.setStopped(false)
-	return g.Server.ListenAndServeTLSConfig(c)
+	return g.serve(func() error {
+		return g.Server.ListenAndServeTLSConfig(c)
+	})
+}
+
+// RunWithContext calls ListenAndServe and stops the server with the given
+// timeout once ctx is done. It returns nil after a shutdown caused by ctx
+// and the serve error otherwise, even if ctx is done.
+func (g *GracefulServer) RunWithContext(ctx context.Context, timeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- g.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	sc := g.StopChan()
+	g.Stop(timeout)
+	err := <-errc
+	<-sc
+
+	// Serve may return an error because the listener was closed, which is
+	// expected, but others (e.g. failing to listen) aren't
+	if errors.Is(err, http.ErrServerClosed) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+
+	return err
 }
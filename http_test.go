@@ -2,11 +2,14 @@ package abutil
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -86,6 +89,52 @@ func ExampleRemoteIP() {
 	// Output: New request from 123.456.7.8
 }
 
+func TestRequestScheme(t *testing.T) {
+	headers := []struct {
+		header http.Header
+		scheme string
+	}{
+		{http.Header{}, "http"},
+		{http.Header{"X-Forwarded-Proto": {"https"}}, "https"},
+		{http.Header{"X-Forwarded-Proto": {"HTTPS, http"}}, "https"},
+		{http.Header{"Forwarded": {"for=1.2.3.4;proto=https"}}, "https"},
+		{http.Header{"Forwarded": {`proto="http", proto=https`}}, "http"},
+	}
+
+	for _, h := range headers {
+		mockRequestContext(t, func(r *http.Request) {
+			r.Header = h.header
+
+			out := RequestScheme(r)
+			if out != h.scheme {
+				t.Errorf("Expected %s for %v, but got %s", h.scheme, h.header, out)
+			}
+		})
+	}
+
+	mockRequestContext(t, func(r *http.Request) {
+		r.TLS = &tls.ConnectionState{}
+
+		out := RequestScheme(r)
+		if out != "https" {
+			t.Errorf("Expected https, but got %s", out)
+		}
+	})
+}
+
+func TestRequestHost(t *testing.T) {
+	mockRequestContext(t, func(r *http.Request) {
+		if out := RequestHost(r); out != "some.url" {
+			t.Errorf("Expected some.url, but got %s", out)
+		}
+
+		r.Header.Set("X-Forwarded-Host", "other.url:8080, proxy.url")
+		if out := RequestHost(r); out != "other.url:8080" {
+			t.Errorf("Expected other.url:8080, but got %s", out)
+		}
+	})
+}
+
 func gracefulServerContext(t *testing.T, fn func(*GracefulServer)) {
 	p := 1337
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -178,6 +227,173 @@ func TestGracefulServer(t *testing.T) {
 	})
 }
 
+func TestNewGracefulServerAddr(t *testing.T) {
+	addr := "127.0.0.1:1337"
+	s := NewGracefulServerAddr(addr, http.NotFoundHandler())
+
+	if s.Server.Addr != addr {
+		t.Errorf("Expected Addr to be %s, but got %s", addr, s.Server.Addr)
+	}
+
+	if s.Server.NoSignalHandling != true {
+		t.Error("NoSignalHandling should be true")
+	}
+
+	if !s.Stopped() {
+		t.Error("Stopped returned false, but shouldn't")
+	}
+}
+
+func TestGracefulServerGo(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewGracefulServerAddr(l.Addr().String(), http.NotFoundHandler())
+
+	started := make(chan struct{})
+	var finished int32
+	s.Go(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+
+		// Some cleanup
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		s.Serve(l)
+		close(done)
+	}()
+
+	<-started
+	s.Stop(time.Second)
+	<-done
+
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Error("Expected Stop to wait for the task")
+	}
+}
+
+func TestGracefulServerStopNoTimeout(t *testing.T) {
+	s := NewGracefulServerAddr("127.0.0.1:0", http.NotFoundHandler())
+
+	var finished int32
+	s.Go(func(ctx context.Context) {
+		<-ctx.Done()
+
+		// Some cleanup
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+	})
+
+	// A timeout of 0 waits for the task
+	s.Stop(0)
+
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Error("Expected Stop(0) to wait for the task")
+	}
+}
+
+func TestGracefulServerRestart(t *testing.T) {
+	s := NewGracefulServerAddr("127.0.0.1:0", http.NotFoundHandler())
+
+	for i := 0; i < 2; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			s.Serve(l)
+			close(done)
+		}()
+
+		// Wait for the server to be up
+		resp, err := http.Get("http://" + l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if err := s.ShutdownContext().Err(); err != nil {
+			t.Errorf("Expected a live shutdown context in run %d, but got %v", i, err)
+		}
+
+		taskErr := make(chan error, 1)
+		s.Go(func(ctx context.Context) {
+			taskErr <- ctx.Err()
+		})
+		if err := <-taskErr; err != nil {
+			t.Errorf("Expected the task to run with a live context in run %d, but got %v", i, err)
+		}
+
+		sc := s.StopChan()
+		s.Stop(time.Second)
+		<-done
+		<-sc
+
+		if s.ShutdownContext().Err() == nil {
+			t.Errorf("Expected the shutdown context to be cancelled in run %d", i)
+		}
+	}
+}
+
+func TestGracefulServerStopBeforeServe(t *testing.T) {
+	s := NewGracefulServerAddr("127.0.0.1:0", http.NotFoundHandler())
+	s.Stop(time.Second)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve returns right away instead of undoing the Stop
+	s.Serve(l)
+	if s.ShutdownContext().Err() == nil {
+		t.Error("Expected the shutdown context to stay cancelled")
+	}
+}
+
+func TestGracefulServerRunWithContext(t *testing.T) {
+	gracefulServerContext(t, func(s *GracefulServer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, func() {
+			if s.Stopped() {
+				t.Error("Server should not be stopped when running")
+			}
+
+			cancel()
+		})
+
+		if err := s.RunWithContext(ctx, time.Second); err != nil {
+			t.Errorf("Expected no error, but got %v", err)
+		}
+
+		if !s.Stopped() {
+			t.Error("Stopped returned false after the context was cancelled")
+		}
+	})
+
+	// Serve errors are propagated
+	s := NewGracefulServerAddr("256.0.0.1:1337", http.NotFoundHandler())
+	if err := s.RunWithContext(context.Background(), time.Second); err == nil {
+		t.Error("Expected an error for an invalid address")
+	}
+
+	// Even if they race with the cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s = NewGracefulServerAddr("256.0.0.1:1337", http.NotFoundHandler())
+	if err := s.RunWithContext(ctx, time.Second); err == nil {
+		t.Error("Expected an error for an invalid address after cancelling")
+	}
+}
+
 func ExampleGracefulServer() {
 	s := NewGracefulServer(1337,
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -204,3 +420,77 @@ func ExampleGracefulServer() {
 
 	// Output: Stopping server..bye!
 }
+
+// hijackingServer serves a handler hijacking the connection with fn and
+// returns the server and a connection of a client which sent a request
+func hijackingServer(t *testing.T, fn func(s *GracefulServer, c net.Conn)) (*GracefulServer, net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s *GracefulServer
+	hijacked := make(chan struct{})
+	s = NewGracefulServerAddr(l.Addr().String(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer s.TrackHijacked(c)()
+		close(hijacked)
+
+		fn(s, c)
+	}))
+	go s.Serve(l)
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	<-hijacked
+
+	return s, client
+}
+
+func TestGracefulServerTrackHijacked(t *testing.T) {
+	s, client := hijackingServer(t, func(s *GracefulServer, c net.Conn) {
+		<-s.ShutdownContext().Done()
+		time.Sleep(20 * time.Millisecond)
+
+		c.Write([]byte("bye"))
+		c.Close()
+	})
+	defer client.Close()
+
+	start := time.Now()
+	s.Stop(time.Second)
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Expected Stop to wait for the hijacked connection, but returned after %s", d)
+	}
+
+	if b, _ := io.ReadAll(client); string(b) != "bye" {
+		t.Errorf("Expected bye, but got %q", b)
+	}
+}
+
+func TestGracefulServerCloseHijacked(t *testing.T) {
+	s, client := hijackingServer(t, func(s *GracefulServer, c net.Conn) {
+		// Ignores the shutdown and blocks until the connection is closed
+		io.Copy(io.Discard, c)
+	})
+	defer client.Close()
+
+	s.CloseHijacked = true
+
+	start := time.Now()
+	s.Stop(5 * time.Second)
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Expected Stop to close the hijacked connection, but took %s", d)
+	}
+
+	if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected the connection to be closed, but got %v", err)
+	}
+}
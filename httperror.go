@@ -0,0 +1,65 @@
+package abutil
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+// HTTPError is an error with an HTTP status code and a message that's safe
+// to show to clients. Return it from your service layer and respond with
+// WriteHTTPError.
+type HTTPError struct {
+	// Status is the HTTP status code
+	Status int
+
+	// Message is shown to the client
+	Message string
+
+	// Err is the underlying error, if any, which is not shown to the client
+	Err error
+}
+
+// NewHTTPError returns an *HTTPError with the given status and message
+func NewHTTPError(status int, msg string) *HTTPError {
+	return &HTTPError{Status: status, Message: msg}
+}
+
+// WrapHTTPError returns an *HTTPError wrapping err, with the status text as
+// message
+func WrapHTTPError(status int, err error) *HTTPError {
+	return &HTTPError{Status: status, Message: http.StatusText(status), Err: err}
+}
+
+// Error returns the message and the underlying error, if any
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+// Unwrap returns the underlying error
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// WriteHTTPError responds with the status and message of err, if it is or
+// wraps an *HTTPError. Other errors are logged and result in a generic 500
+// Internal Server Error, so internals don't leak to the client.
+func WriteHTTPError(w http.ResponseWriter, err error) {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		if he.Status >= 500 {
+			log.Printf("abutil: %v", err)
+		}
+
+		http.Error(w, he.Message, he.Status)
+		return
+	}
+
+	log.Printf("abutil: %v", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError),
+		http.StatusInternalServerError)
+}
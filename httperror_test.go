@@ -0,0 +1,67 @@
+package abutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHTTPError(t *testing.T) {
+	err := NewHTTPError(http.StatusNotFound, "user not found")
+	if err.Error() != "user not found" || err.Unwrap() != nil {
+		t.Errorf("Expected user not found, but got %q (%v)", err, err.Unwrap())
+	}
+
+	cause := errors.New("connection refused")
+	err = WrapHTTPError(http.StatusServiceUnavailable, cause)
+	if err.Error() != "Service Unavailable: connection refused" {
+		t.Errorf("Expected the status text and cause, but got %q", err)
+	}
+
+	if !errors.Is(err, cause) {
+		t.Error("Expected errors.Is to find the cause")
+	}
+}
+
+func TestWriteHTTPError(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	cases := []struct {
+		err    error
+		status int
+		body   string
+		logged bool
+	}{
+		{NewHTTPError(http.StatusNotFound, "user not found"), 404, "user not found", false},
+		{fmt.Errorf("loading user: %w", NewHTTPError(http.StatusForbidden, "nope")), 403, "nope", false},
+		{WrapHTTPError(http.StatusBadGateway, errors.New("secret upstream")), 502, "Bad Gateway", true},
+		{errors.New("secret database error"), 500, "Internal Server Error", true},
+	}
+
+	for _, c := range cases {
+		buf.Reset()
+		w := httptest.NewRecorder()
+		WriteHTTPError(w, c.err)
+
+		if w.Code != c.status || strings.TrimSpace(w.Body.String()) != c.body {
+			t.Errorf("Expected %d %q for %v, but got %d %q", c.status, c.body,
+				c.err, w.Code, w.Body)
+		}
+
+		if strings.Contains(w.Body.String(), "secret") {
+			t.Errorf("Expected the body not to leak the error, but got %q", w.Body)
+		}
+
+		if logged := buf.Len() > 0; logged != c.logged {
+			t.Errorf("Expected logged to be %t for %v, but got %q", c.logged, c.err, buf.String())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package abutil
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidRange is returned by ParseRange for malformed headers
+	ErrInvalidRange = errors.New("abutil: invalid range")
+
+	// ErrRangeNotSatisfiable is returned by ParseRange if none of the ranges
+	// overlap the content, reply with a 416 then
+	ErrRangeNotSatisfiable = errors.New("abutil: range not satisfiable")
+)
+
+// Range is a byte range of a Range header
+type Range struct {
+	Start  int64
+	Length int64
+}
+
+// ParseRange parses a Range header like "bytes=0-499,-500,1000-" for content
+// of the given size. Ranges exceeding the content are truncated and ranges
+// starting after it are dropped. An empty header results in nil.
+func ParseRange(header string, size int64) ([]Range, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, ErrInvalidRange
+	}
+
+	var (
+		ranges []Range
+		parsed bool
+	)
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		parsed = true
+
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r Range
+		if first == "" {
+			// Suffix range: the last n bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, ErrInvalidRange
+			}
+
+			if n == 0 {
+				continue
+			}
+
+			if n > size {
+				n = size
+			}
+
+			r = Range{Start: size - n, Length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, ErrInvalidRange
+			}
+
+			end := size - 1
+			if last != "" {
+				end, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, ErrInvalidRange
+				}
+
+				if end >= size {
+					end = size - 1
+				}
+			}
+
+			if start >= size {
+				continue
+			}
+
+			r = Range{Start: start, Length: end - start + 1}
+		}
+
+		if r.Length > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+
+	// A header without any spec is invalid, not unsatisfiable
+	if !parsed {
+		return nil, ErrInvalidRange
+	}
+
+	if len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable
+	}
+
+	return ranges, nil
+}
@@ -0,0 +1,50 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		header   string
+		expected string
+		err      error
+	}{
+		{"", "[]", nil},
+		{"bytes=0-499", "[{0 500}]", nil},
+		{"bytes=500-999", "[{500 500}]", nil},
+		{"bytes=-500", "[{500 500}]", nil},
+		{"bytes=900-", "[{900 100}]", nil},
+		{"bytes=-2000", "[{0 1000}]", nil},
+		{"bytes=900-2000", "[{900 100}]", nil},
+		{"bytes=0-0, 10-19,-1", "[{0 1} {10 10} {999 1}]", nil},
+		// Overlapping ranges are kept as is
+		{"bytes=0-99,50-149", "[{0 100} {50 100}]", nil},
+		{"bytes=1000-", "[]", ErrRangeNotSatisfiable},
+		{"bytes=5000-6000,-0", "[]", ErrRangeNotSatisfiable},
+		{"bytes=0-99,1000-", "[{0 100}]", nil},
+		{"bytes=99-0", "[]", ErrInvalidRange},
+		{"bytes=abc", "[]", ErrInvalidRange},
+		{"bytes=a-b", "[]", ErrInvalidRange},
+		{"bytes=--1", "[]", ErrInvalidRange},
+		{"items=0-1", "[]", ErrInvalidRange},
+		{"bytes=", "[]", ErrInvalidRange},
+		{"bytes=, ,", "[]", ErrInvalidRange},
+	}
+
+	for _, c := range cases {
+		out, err := ParseRange(c.header, 1000)
+		if err != c.err || fmt.Sprint(out) != c.expected {
+			t.Errorf("Expected (%s, %v) for %q, but got (%v, %v)", c.expected,
+				c.err, c.header, out, err)
+		}
+	}
+}
+
+func ExampleParseRange() {
+	ranges, err := ParseRange("bytes=0-99,-100", 1000)
+	fmt.Println(ranges, err)
+
+	// Output: [{0 100} {900 100}] <nil>
+}
@@ -0,0 +1,145 @@
+package abutil
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response captured by a middleware to be replayed
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// write replays the response
+func (c *CachedResponse) write(w http.ResponseWriter) {
+	for k, v := range c.Header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+
+	w.WriteHeader(c.Status)
+	w.Write(c.Body)
+}
+
+// IdempotencyStore stores the responses of IdempotencyMiddleware. It must be
+// safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the response stored for key, if any and not expired
+	Get(key string) (*CachedResponse, bool)
+
+	// Set stores the response for key for the given duration
+	Set(key string, resp *CachedResponse, ttl time.Duration)
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore. Expired entries
+// are removed on access. The zero value is ready to use.
+type MemoryIdempotencyStore struct {
+	m       sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+}
+
+type memoryIdempotencyEntry struct {
+	resp    *CachedResponse
+	expires time.Time
+}
+
+// Get returns the response stored for key, if it's not expired
+func (s *MemoryIdempotencyStore) Get(key string) (*CachedResponse, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(e.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+
+	return e.resp, true
+}
+
+// Set stores the response for key for the given duration
+func (s *MemoryIdempotencyStore) Set(key string, resp *CachedResponse, ttl time.Duration) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.entries == nil {
+		s.entries = make(map[string]memoryIdempotencyEntry)
+	}
+
+	now := time.Now()
+	for k, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.entries[key] = memoryIdempotencyEntry{resp, now.Add(ttl)}
+}
+
+// IdempotencyMiddleware dedupes requests with the same Idempotency-Key
+// header (per method and path): the first one is handled and its response
+// stored for ttl, later ones get the stored response with an
+// Idempotent-Replayed header, without calling the handler. While a request
+// is handled, others with the same key get a 409 Conflict. 5xx responses
+// aren't stored, so they can be retried. Requests without the header are
+// passed through.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	var (
+		m        sync.Mutex
+		inflight = map[string]bool{}
+	)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ik := r.Header.Get("Idempotency-Key")
+			if ik == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			key := r.Method + " " + r.URL.Path + " " + ik
+
+			m.Lock()
+			if inflight[key] {
+				m.Unlock()
+				http.Error(w, "A request with this Idempotency-Key is in progress",
+					http.StatusConflict)
+				return
+			}
+
+			// Check under the lock, so a finishing request can't store its
+			// response in between
+			if resp, ok := store.Get(key); ok {
+				m.Unlock()
+				w.Header().Set("Idempotent-Replayed", "true")
+				resp.write(w)
+				return
+			}
+
+			inflight[key] = true
+			m.Unlock()
+
+			defer func() {
+				m.Lock()
+				delete(inflight, key)
+				m.Unlock()
+			}()
+
+			tw := &teeWriter{StatusWriter: NewStatusWriter(w)}
+			h.ServeHTTP(tw, r)
+
+			if tw.Status() < 500 {
+				store.Set(key, &CachedResponse{
+					Status: tw.Status(),
+					Header: w.Header().Clone(),
+					Body:   tw.body.Bytes(),
+				}, ttl)
+			}
+		})
+	}
+}
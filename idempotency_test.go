@@ -0,0 +1,121 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func idempotencyRequest(h http.Handler, key string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/payments", nil)
+	if key != "" {
+		r.Header.Set("Idempotency-Key", key)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var calls int32
+	h := IdempotencyMiddleware(&MemoryIdempotencyStore{}, time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&calls, 1)
+			w.Header().Set("X-Call", string(rune('0'+n)))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created"))
+		}))
+
+	w := idempotencyRequest(h, "foo")
+	if w.Code != http.StatusCreated || w.Body.String() != "created" ||
+		w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected the handler's response, but got %d %q %v", w.Code, w.Body, w.Header())
+	}
+
+	// Replay
+	w = idempotencyRequest(h, "foo")
+	if w.Code != http.StatusCreated || w.Body.String() != "created" ||
+		w.Header().Get("X-Call") != "1" || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected the replayed response, but got %d %q %v", w.Code, w.Body, w.Header())
+	}
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected the handler to be called once, but got %d", calls)
+	}
+
+	// Other and missing keys aren't replayed
+	idempotencyRequest(h, "bar")
+	idempotencyRequest(h, "")
+	idempotencyRequest(h, "")
+	if atomic.LoadInt32(&calls) != 4 {
+		t.Errorf("Expected the handler to be called 4 times, but got %d", calls)
+	}
+}
+
+func TestIdempotencyMiddlewareConcurrent(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := IdempotencyMiddleware(&MemoryIdempotencyStore{}, time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.Write([]byte("done"))
+		}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- idempotencyRequest(h, "foo")
+	}()
+
+	<-started
+	if w := idempotencyRequest(h, "foo"); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a concurrent request, but got %d", w.Code)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusOK || w.Body.String() != "done" {
+		t.Errorf("Expected the first request to succeed, but got %d %q", w.Code, w.Body)
+	}
+
+	if w := idempotencyRequest(h, "foo"); w.Body.String() != "done" {
+		t.Errorf("Expected a replay afterwards, but got %d %q", w.Code, w.Body)
+	}
+}
+
+func TestIdempotencyMiddlewareServerError(t *testing.T) {
+	var calls int32
+	h := IdempotencyMiddleware(&MemoryIdempotencyStore{}, time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+	idempotencyRequest(h, "foo")
+	idempotencyRequest(h, "foo")
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected 5xx responses not to be stored, but got %d calls", calls)
+	}
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	var s MemoryIdempotencyStore
+	if _, ok := s.Get("foo"); ok {
+		t.Error("Expected an empty store")
+	}
+
+	resp := &CachedResponse{Status: 200}
+	s.Set("foo", resp, time.Minute)
+	s.Set("bar", resp, -time.Second)
+
+	if got, ok := s.Get("foo"); !ok || got != resp {
+		t.Errorf("Expected the stored response, but got %v", got)
+	}
+
+	if _, ok := s.Get("bar"); ok {
+		t.Error("Expected expired responses to be removed")
+	}
+}
@@ -0,0 +1,202 @@
+package abutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+)
+
+// progressInterval is the minimum time between two progress callbacks
+const progressInterval = 100 * time.Millisecond
+
+// CloseAll closes all given closers, even if some of them fail, and returns
+// the joined errors or nil. Nil closers are skipped.
+func CloseAll(closers ...io.Closer) error {
+	var errs []error
+	for _, c := range closers {
+		if c == nil {
+			continue
+		}
+
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CloseQuietly is like CloseAll, but logs the error instead of returning it.
+// This is useful in defers where an error can't be returned.
+func CloseQuietly(closers ...io.Closer) {
+	if err := CloseAll(closers...); err != nil {
+		log.Printf("abutil: error closing: %v", err)
+	}
+}
+
+// CopyProgress copies from src to dst like io.Copy and calls onProgress with
+// the number of bytes written so far. The callback is throttled to once per
+// 100ms, but always called after the copy has finished.
+func CopyProgress(dst io.Writer, src io.Reader, onProgress func(written int64)) (int64, error) {
+	pw := &progressWriter{w: dst, fn: onProgress}
+	n, err := io.Copy(pw, src)
+	if pw.reported != n {
+		onProgress(n)
+	}
+
+	return n, err
+}
+
+// CopyProgressTotal is like CopyProgress, but passes the expected total size
+// to the callback as well, so it can compute a percentage
+func CopyProgressTotal(dst io.Writer, src io.Reader, total int64, onProgress func(written, total int64)) (int64, error) {
+	return CopyProgress(dst, src, func(written int64) {
+		onProgress(written, total)
+	})
+}
+
+// progressWriter counts the written bytes and calls fn at most every
+// progressInterval
+type progressWriter struct {
+	w  io.Writer
+	fn func(int64)
+
+	// written is the number of bytes written, reported the one passed to fn
+	written  int64
+	reported int64
+	last     time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.reported = p.written
+		p.fn(p.written)
+	}
+
+	return n, err
+}
+
+// NewRateLimitedReader returns an io.Reader which reads from r with at most
+// bytesPerSec bytes per second
+func NewRateLimitedReader(r io.Reader, bytesPerSec int) io.Reader {
+	return NewRateLimitedReaderContext(context.Background(), r, bytesPerSec)
+}
+
+// NewRateLimitedReaderContext is like NewRateLimitedReader, but stops
+// waiting and returns the context error once ctx is done
+func NewRateLimitedReaderContext(ctx context.Context, r io.Reader, bytesPerSec int) io.Reader {
+	return &rateLimitedReader{r: r, b: newByteBucket(ctx, bytesPerSec)}
+}
+
+// NewRateLimitedWriter returns an io.Writer which writes to w with at most
+// bytesPerSec bytes per second
+func NewRateLimitedWriter(w io.Writer, bytesPerSec int) io.Writer {
+	return NewRateLimitedWriterContext(context.Background(), w, bytesPerSec)
+}
+
+// NewRateLimitedWriterContext is like NewRateLimitedWriter, but stops
+// waiting and returns the context error once ctx is done
+func NewRateLimitedWriterContext(ctx context.Context, w io.Writer, bytesPerSec int) io.Writer {
+	return &rateLimitedWriter{w: w, b: newByteBucket(ctx, bytesPerSec)}
+}
+
+type rateLimitedReader struct {
+	r io.Reader
+	b *byteBucket
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.b.burst {
+		p = p[:r.b.burst]
+	}
+
+	n, err := r.r.Read(p)
+	if werr := r.b.take(n); werr != nil {
+		return n, werr
+	}
+
+	return n, err
+}
+
+type rateLimitedWriter struct {
+	w io.Writer
+	b *byteBucket
+}
+
+func (w *rateLimitedWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > w.b.burst {
+			chunk = chunk[:w.b.burst]
+		}
+
+		if err := w.b.take(len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := w.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		p = p[n:]
+	}
+
+	return written, nil
+}
+
+// byteBucket is a token bucket with one token per byte. It starts empty and
+// holds at most one second worth of tokens.
+type byteBucket struct {
+	ctx    context.Context
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+func newByteBucket(ctx context.Context, bytesPerSec int) *byteBucket {
+	if bytesPerSec < 1 {
+		bytesPerSec = 1
+	}
+
+	return &byteBucket{
+		ctx:   ctx,
+		rate:  float64(bytesPerSec),
+		burst: bytesPerSec,
+		last:  time.Now(),
+	}
+}
+
+// take consumes n tokens, waiting until they're available
+func (b *byteBucket) take(n int) error {
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > float64(b.burst) {
+		b.tokens = float64(b.burst)
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	}
+}
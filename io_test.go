@@ -0,0 +1,222 @@
+package abutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type mockCloser struct {
+	closed bool
+	err    error
+}
+
+func (m *mockCloser) Close() error {
+	m.closed = true
+	return m.err
+}
+
+func TestCloseAll(t *testing.T) {
+	err1 := errors.New("Some error")
+	err2 := errors.New("Some other error")
+
+	a := &mockCloser{err: err1}
+	b := &mockCloser{}
+	c := &mockCloser{err: err2}
+
+	err := CloseAll(a, nil, b, c)
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Errorf("Expected error to contain %v and %v, but got %v", err1, err2,
+			err)
+	}
+
+	for i, m := range []*mockCloser{a, b, c} {
+		if !m.closed {
+			t.Errorf("Expected closer %d to be closed", i)
+		}
+	}
+
+	if err := CloseAll(&mockCloser{}, nil); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+
+	if err := CloseAll(); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+}
+
+func TestCloseQuietly(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	m := &mockCloser{err: errors.New("Some error")}
+	CloseQuietly(m, nil)
+
+	if !m.closed {
+		t.Error("Expected closer to be closed")
+	}
+
+	if !strings.Contains(buf.String(), "Some error") {
+		t.Errorf("Expected log to contain the error, but got %q", buf.String())
+	}
+}
+
+func ExampleCloseAll() {
+	a := io.NopCloser(strings.NewReader("foo"))
+	b := io.NopCloser(strings.NewReader("bar"))
+
+	fmt.Println(CloseAll(a, b, nil))
+
+	// Output: <nil>
+}
+
+// slowReader sleeps before every read
+type slowReader struct {
+	r io.Reader
+	d time.Duration
+}
+
+func (s *slowReader) Read(b []byte) (int, error) {
+	time.Sleep(s.d)
+	return s.r.Read(b)
+}
+
+func TestCopyProgress(t *testing.T) {
+	in := strings.Repeat("x", 50)
+	src := &slowReader{
+		r: iotest.OneByteReader(strings.NewReader(in)),
+		d: 5 * time.Millisecond,
+	}
+
+	var calls []int64
+	var buf bytes.Buffer
+	n, err := CopyProgress(&buf, src, func(w int64) {
+		calls = append(calls, w)
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if n != int64(len(in)) || buf.String() != in {
+		t.Errorf("Expected %d bytes to be copied, but got %d", len(in), n)
+	}
+
+	if len(calls) < 2 || len(calls) > 10 {
+		t.Errorf("Expected between 2 and 10 throttled callbacks, but got %d",
+			len(calls))
+	}
+
+	for i := 1; i < len(calls); i++ {
+		if calls[i] < calls[i-1] {
+			t.Errorf("Expected increasing values, but got %v", calls)
+		}
+	}
+
+	if last := calls[len(calls)-1]; last != n {
+		t.Errorf("Expected last callback with %d, but got %d", n, last)
+	}
+
+	// Errors from both sides
+	rerr := errors.New("Some read error")
+	_, err = CopyProgress(io.Discard, iotest.ErrReader(rerr), func(int64) {})
+	if err != rerr {
+		t.Errorf("Expected %v, but got %v", rerr, err)
+	}
+
+	werr := errors.New("Some write error")
+	_, err = CopyProgress(&errWriter{werr}, strings.NewReader("foo"),
+		func(int64) {})
+	if err != werr {
+		t.Errorf("Expected %v, but got %v", werr, err)
+	}
+}
+
+type errWriter struct {
+	err error
+}
+
+func (e *errWriter) Write(b []byte) (int, error) {
+	return 0, e.err
+}
+
+func TestCopyProgressTotal(t *testing.T) {
+	var written, total int64
+	CopyProgressTotal(io.Discard, strings.NewReader("foobar"), 6,
+		func(w, t int64) {
+			written, total = w, t
+		})
+
+	if written != 6 || total != 6 {
+		t.Errorf("Expected 6 of 6, but got %d of %d", written, total)
+	}
+}
+
+func ExampleCopyProgressTotal() {
+	src := strings.NewReader("Some large download")
+
+	CopyProgressTotal(io.Discard, src, src.Size(), func(written, total int64) {
+		fmt.Printf("%d%%\n", written*100/total)
+	})
+
+	// Output: 100%
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	in := strings.Repeat("x", 2000)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, NewRateLimitedReader(strings.NewReader(in),
+		10000))
+	d := time.Since(start)
+
+	if err != nil || n != int64(len(in)) {
+		t.Errorf("Expected to copy %d bytes, but got %d (%v)", len(in), n, err)
+	}
+
+	if d < 150*time.Millisecond || d > 500*time.Millisecond {
+		t.Errorf("Expected copy to take about 200ms, but took %s", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	r := NewRateLimitedReaderContext(ctx, strings.NewReader(in), 100)
+	_, err = io.Copy(io.Discard, r)
+	if err != context.Canceled {
+		t.Errorf("Expected %v, but got %v", context.Canceled, err)
+	}
+}
+
+func TestRateLimitedWriter(t *testing.T) {
+	in := strings.Repeat("x", 2000)
+
+	var buf bytes.Buffer
+	start := time.Now()
+	n, err := NewRateLimitedWriter(&buf, 10000).Write([]byte(in))
+	d := time.Since(start)
+
+	if err != nil || n != len(in) || buf.String() != in {
+		t.Errorf("Expected to write %d bytes, but got %d (%v)", len(in), n, err)
+	}
+
+	if d < 150*time.Millisecond || d > 500*time.Millisecond {
+		t.Errorf("Expected write to take about 200ms, but took %s", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err = NewRateLimitedWriterContext(ctx, io.Discard, 100).Write([]byte(in))
+	if err != context.Canceled {
+		t.Errorf("Expected %v, but got %v", context.Canceled, err)
+	}
+}
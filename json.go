@@ -0,0 +1,156 @@
+package abutil
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+)
+
+// DecodeJSONWithDefaults decodes data into a copy of defaults, so fields
+// missing in the JSON keep their default instead of becoming the zero value.
+// Note that maps and pointers in defaults are shared with the result and
+// decoded into.
+func DecodeJSONWithDefaults[T any](data []byte, defaults T) (T, error) {
+	v := defaults
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return defaults, err
+	}
+
+	return v, nil
+}
+
+// maxSanitizeDepth stops SanitizeFloats at cyclic values, which
+// json.Marshal reports as error
+const maxSanitizeDepth = 1000
+
+// SanitizeFloats returns a copy of v with NaN and ±Inf floats, which
+// encoding/json can't marshal, replaced: with nil (null in JSON) in
+// interfaces (e.g. map[string]interface{}) and pointers, with sentinel
+// otherwise. Structs, maps, slices, arrays and pointers are copied as needed,
+// v isn't modified. Values implementing json.Marshaler or
+// encoding.TextMarshaler and unexported fields are kept as is.
+func SanitizeFloats(v interface{}, sentinel float64) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	return sanitizeFloats(reflect.ValueOf(v), sentinel, 0).Interface()
+}
+
+// MarshalJSONSafe is like json.Marshal, but marshals SanitizeFloats(v, 0) if
+// v contains NaN or ±Inf floats
+func MarshalJSONSafe(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+
+	var uve *json.UnsupportedValueError
+	if errors.As(err, &uve) {
+		return json.Marshal(SanitizeFloats(v, 0))
+	}
+
+	return b, err
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func sanitizeFloats(v reflect.Value, sentinel float64, depth int) reflect.Value {
+	t := v.Type()
+	if depth > maxSanitizeDepth || t.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) {
+		return v
+	}
+	depth++
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if isInvalidFloat(v) {
+			return reflect.ValueOf(sentinel).Convert(t)
+		}
+
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		if isInvalidFloat(v.Elem()) {
+			return reflect.Zero(t)
+		}
+
+		elem := sanitizeFloats(v.Elem(), sentinel, depth)
+		if v.Kind() == reflect.Interface {
+			out := reflect.New(t).Elem()
+			out.Set(elem)
+			return out
+		}
+
+		out := reflect.New(t.Elem())
+		out.Elem().Set(elem)
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(sanitizeFloats(v.Field(i), sentinel, depth))
+			}
+		}
+		return out
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() || isFloatFree(t.Elem()) {
+			return v
+		}
+
+		out := reflect.New(t).Elem()
+		if v.Kind() == reflect.Slice {
+			out = reflect.MakeSlice(t, v.Len(), v.Len())
+		}
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(sanitizeFloats(v.Index(i), sentinel, depth))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() || isFloatFree(t.Elem()) {
+			return v
+		}
+
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), sanitizeFloats(iter.Value(), sentinel, depth))
+		}
+		return out
+	}
+
+	return v
+}
+
+// isInvalidFloat reports if v is a NaN or ±Inf float
+func isInvalidFloat(v reflect.Value) bool {
+	if k := v.Kind(); k != reflect.Float32 && k != reflect.Float64 {
+		return false
+	}
+
+	f := v.Float()
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+// isFloatFree reports if values of t can't contain floats
+func isFloatFree(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8,
+		reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,151 @@
+package abutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+type jsonDefaultsConfig struct {
+	Host    string        `json:"host"`
+	Port    int           `json:"port"`
+	Debug   bool          `json:"debug"`
+	Timeout time.Duration `json:"timeout"`
+	Nested  struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	} `json:"nested"`
+}
+
+func TestDecodeJSONWithDefaults(t *testing.T) {
+	defaults := jsonDefaultsConfig{
+		Host:    "localhost",
+		Port:    8080,
+		Debug:   true,
+		Timeout: time.Second,
+	}
+	defaults.Nested.A = 1
+	defaults.Nested.B = 2
+
+	c, err := DecodeJSONWithDefaults([]byte(`{"port": 0, "host": "example.com", "nested": {"b": 3}}`), defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := defaults
+	expected.Host = "example.com"
+	expected.Port = 0
+	expected.Nested.B = 3
+	if c != expected {
+		t.Errorf("Expected %+v, but got %+v", expected, c)
+	}
+
+	if defaults.Host != "localhost" || defaults.Nested.B != 2 {
+		t.Error("Expected defaults to be unchanged")
+	}
+
+	c, err = DecodeJSONWithDefaults([]byte(`{"port": "nope"}`), defaults)
+	if err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+
+	if c != defaults {
+		t.Errorf("Expected the defaults on error, but got %+v", c)
+	}
+}
+
+func ExampleDecodeJSONWithDefaults() {
+	type config struct {
+		Port  int  `json:"port"`
+		Debug bool `json:"debug"`
+	}
+
+	c, _ := DecodeJSONWithDefaults([]byte(`{"debug": false}`),
+		config{Port: 8080, Debug: true})
+
+	fmt.Printf("%+v", c)
+	// Output: {Port:8080 Debug:false}
+}
+
+type sanitizeItem struct {
+	Score   float64            `json:"score"`
+	Ratio   *float64           `json:"ratio"`
+	Weights []float32          `json:"weights"`
+	Extra   map[string]float64 `json:"extra"`
+	At      time.Time          `json:"at"`
+	hidden  float64
+}
+
+type sanitizeResponse struct {
+	Items  []sanitizeItem           `json:"items"`
+	Meta   map[string]interface{}   `json:"meta"`
+	Any    interface{}              `json:"any"`
+	Fixed  [2]float64               `json:"fixed"`
+	Nested map[string][]interface{} `json:"nested"`
+}
+
+func TestMarshalJSONSafe(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	ratio := 0.5
+
+	v := sanitizeResponse{
+		Items: []sanitizeItem{
+			{Score: nan, Ratio: &inf, Weights: []float32{1, float32(math.Inf(-1))},
+				Extra: map[string]float64{"a": nan}, hidden: nan},
+			{Score: 1.5, Ratio: &ratio},
+		},
+		Meta:   map[string]interface{}{"avg": nan, "count": 2},
+		Any:    inf,
+		Fixed:  [2]float64{nan, 2},
+		Nested: map[string][]interface{}{"x": {1.0, nan}},
+	}
+
+	if _, err := json.Marshal(v); err == nil {
+		t.Fatal("Expected json.Marshal to fail")
+	}
+
+	b, err := MarshalJSONSafe(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"items":[{"score":0,"ratio":null,"weights":[1,0],"extra":{"a":0},` +
+		`"at":"0001-01-01T00:00:00Z"},{"score":1.5,"ratio":0.5,"weights":null,` +
+		`"extra":null,"at":"0001-01-01T00:00:00Z"}],"meta":{"avg":null,"count":2},` +
+		`"any":null,"fixed":[0,2],"nested":{"x":[1,null]}}`
+	if string(b) != expected {
+		t.Errorf("Expected %s, but got %s", expected, b)
+	}
+
+	// v isn't modified
+	if !math.IsNaN(v.Items[0].Score) || !math.IsInf(*v.Items[0].Ratio, 1) ||
+		!math.IsNaN(v.Meta["avg"].(float64)) || !math.IsNaN(v.Items[0].Extra["a"]) ||
+		!math.IsNaN(v.Fixed[0]) {
+		t.Error("Expected the original value to be unchanged")
+	}
+
+	// Values without invalid floats aren't copied
+	if b, err := MarshalJSONSafe(map[string]int{"a": 1}); err != nil || string(b) != `{"a":1}` {
+		t.Errorf("Expected {\"a\":1}, but got %s (%v)", b, err)
+	}
+}
+
+func TestSanitizeFloatsSentinel(t *testing.T) {
+	out := SanitizeFloats(&sanitizeItem{Score: math.NaN()}, -1).(*sanitizeItem)
+	if out.Score != -1 {
+		t.Errorf("Expected the sentinel -1, but got %v", out.Score)
+	}
+
+	if SanitizeFloats(nil, -1) != nil {
+		t.Error("Expected nil for nil")
+	}
+}
+
+func ExampleMarshalJSONSafe() {
+	b, _ := MarshalJSONSafe(map[string]interface{}{"avg": math.NaN()})
+	fmt.Println(string(b))
+
+	// Output: {"avg":null}
+}
@@ -0,0 +1,51 @@
+package abutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// EncodeJSONLines writes each item received from the channel as compact JSON
+// followed by a newline (JSON Lines / ndjson) until the channel is closed.
+// It returns on the first error, leaving the remaining items in the channel.
+func EncodeJSONLines(w io.Writer, items <-chan interface{}) error {
+	enc := json.NewEncoder(w)
+	for item := range items {
+		// Encode already terminates each value with a newline
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DecodeJSONLines reads newline-delimited JSON from r and calls fn with each
+// record. Blank lines are skipped and there's no limit on the line length.
+// It stops on the first invalid record or error returned by fn.
+func DecodeJSONLines(r io.Reader, fn func(json.RawMessage) error) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if l := bytes.TrimSpace(line); len(l) > 0 {
+			if !json.Valid(l) {
+				return fmt.Errorf("abutil: invalid JSON on line %d", n)
+			}
+
+			if err := fn(json.RawMessage(l)); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
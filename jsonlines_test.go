@@ -0,0 +1,121 @@
+package abutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type jsonLinesRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestJSONLines(t *testing.T) {
+	records := []jsonLinesRecord{{1, "foo"}, {2, "bar"}, {3, strings.Repeat("x", 100000)}}
+
+	items := make(chan interface{})
+	go func() {
+		for _, r := range records {
+			items <- r
+		}
+		close(items)
+	}()
+
+	var buf bytes.Buffer
+	if err := EncodeJSONLines(&buf, items); err != nil {
+		t.Error(err)
+	}
+
+	if n := strings.Count(buf.String(), "\n"); n != len(records) {
+		t.Errorf("Expected %d lines, but got %d", len(records), n)
+	}
+
+	// Add some blank lines
+	in := "\n" + strings.Replace(buf.String(), "\n", "\n  \n", 1)
+
+	var out []jsonLinesRecord
+	err := DecodeJSONLines(strings.NewReader(in), func(m json.RawMessage) error {
+		var r jsonLinesRecord
+		if err := json.Unmarshal(m, &r); err != nil {
+			return err
+		}
+
+		out = append(out, r)
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(out) != len(records) {
+		t.Fatalf("Expected %d records, but got %d", len(records), len(out))
+	}
+
+	for i := range records {
+		if out[i] != records[i] {
+			t.Errorf("Expected record %d to be %v, but got %v", i, records[i].ID,
+				out[i].ID)
+		}
+	}
+}
+
+func TestDecodeJSONLinesErrors(t *testing.T) {
+	fnErr := errors.New("Some error")
+	calls := 0
+	err := DecodeJSONLines(strings.NewReader("{}\n{}\n{}"),
+		func(json.RawMessage) error {
+			calls++
+			return fnErr
+		})
+	if err != fnErr || calls != 1 {
+		t.Errorf("Expected error %v after 1 call, but got %v after %d", fnErr,
+			err, calls)
+	}
+
+	err = DecodeJSONLines(strings.NewReader("{}\n{foo\n"),
+		func(json.RawMessage) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error on line 2, but got %v", err)
+	}
+
+	// Without trailing newline
+	calls = 0
+	DecodeJSONLines(strings.NewReader(`{"id":1}`), func(json.RawMessage) error {
+		calls++
+		return nil
+	})
+	if calls != 1 {
+		t.Errorf("Expected 1 call, but got %d", calls)
+	}
+}
+
+func ExampleEncodeJSONLines() {
+	items := make(chan interface{}, 2)
+	items <- map[string]int{"id": 1}
+	items <- map[string]int{"id": 2}
+	close(items)
+
+	EncodeJSONLines(os.Stdout, items)
+
+	// Output:
+	// {"id":1}
+	// {"id":2}
+}
+
+func ExampleDecodeJSONLines() {
+	in := strings.NewReader("{\"id\":1}\n\n{\"id\":2}\n")
+
+	DecodeJSONLines(in, func(m json.RawMessage) error {
+		fmt.Println(string(m))
+		return nil
+	})
+
+	// Output:
+	// {"id":1}
+	// {"id":2}
+}
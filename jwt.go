@@ -0,0 +1,116 @@
+package abutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// The errors returned by ParseJWTHS256
+var (
+	ErrInvalidJWT     = errors.New("abutil: invalid JWT")
+	ErrJWTSignature   = errors.New("abutil: invalid JWT signature")
+	ErrJWTExpired     = errors.New("abutil: JWT is expired")
+	ErrJWTNotYetValid = errors.New("abutil: JWT is not valid yet")
+	ErrJWTUnsupported = errors.New("abutil: unsupported JWT algorithm")
+)
+
+// ParseJWTHS256 verifies a JWT signed with HMAC-SHA256 and returns its
+// claims, see ParseJWTHS256Leeway
+func ParseJWTHS256(token string, secret []byte) (map[string]interface{}, error) {
+	return ParseJWTHS256Leeway(token, secret, 0)
+}
+
+// ParseJWTHS256Leeway verifies a JWT signed with HMAC-SHA256 and returns its
+// claims. Tokens with another alg header are rejected, so the token can't
+// choose a weaker verification (e.g. "none"). The exp and nbf claims are
+// checked if present, allowing the given leeway for clock skew.
+func ParseJWTHS256Leeway(token string, secret []byte, leeway time.Duration) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidJWT
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	if header.Alg != "HS256" {
+		return nil, ErrJWTUnsupported
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidJWT
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrJWTSignature
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	exp, hasExp, err := jwtTime(claims, "exp")
+	if err != nil {
+		return nil, err
+	}
+
+	nbf, hasNbf, err := jwtTime(claims, "nbf")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if hasExp && !now.Before(exp.Add(leeway)) {
+		return nil, ErrJWTExpired
+	}
+
+	if hasNbf && now.Before(nbf.Add(-leeway)) {
+		return nil, ErrJWTNotYetValid
+	}
+
+	return claims, nil
+}
+
+func decodeJWTSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ErrInvalidJWT
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrInvalidJWT
+	}
+
+	return nil
+}
+
+// jwtTime returns the NumericDate claim name and whether it's set. A claim
+// that isn't a number (in the range of time.Time) results in ErrInvalidJWT.
+func jwtTime(claims map[string]interface{}, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	f, ok := v.(float64)
+	if !ok || f >= math.MaxInt64 || f <= math.MinInt64 {
+		return time.Time{}, false, ErrInvalidJWT
+	}
+
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), true, nil
+}
@@ -0,0 +1,112 @@
+package abutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var jwtSecret = []byte("secret")
+
+func signJWT(header, claims map[string]interface{}, secret []byte) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	s := base64.RawURLEncoding.EncodeToString(h) + "." +
+		base64.RawURLEncoding.EncodeToString(c)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(s))
+
+	return s + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+var hs256Header = map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+
+func TestParseJWTHS256(t *testing.T) {
+	now := time.Now().Unix()
+	token := signJWT(hs256Header, map[string]interface{}{
+		"sub": "1234",
+		"exp": now + 60,
+		"nbf": now - 60,
+	}, jwtSecret)
+
+	claims, err := ParseJWTHS256(token, jwtSecret)
+	if err != nil || claims["sub"] != "1234" {
+		t.Errorf("Expected (sub 1234, <nil>), but got (%v, %v)", claims, err)
+	}
+
+	// Far in the future, beyond the range of UnixNano
+	far := signJWT(hs256Header, map[string]interface{}{
+		"exp": time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	}, jwtSecret)
+	if _, err := ParseJWTHS256(far, jwtSecret); err != nil {
+		t.Errorf("Expected no error for an exp in the year 3000, but got %v", err)
+	}
+
+	// Example token from jwt.io
+	const jwtIO = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+		"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+		"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+	if claims, err := ParseJWTHS256(jwtIO, []byte("your-256-bit-secret")); err != nil || claims["name"] != "John Doe" {
+		t.Errorf("Expected (name John Doe, <nil>), but got (%v, %v)", claims, err)
+	}
+}
+
+func TestParseJWTHS256Errors(t *testing.T) {
+	now := time.Now().Unix()
+	valid := signJWT(hs256Header, map[string]interface{}{"sub": "1234"}, jwtSecret)
+
+	tampered := valid[:len(valid)-2] + "xx"
+	if valid[len(valid)-2:] == "xx" {
+		tampered = valid[:len(valid)-2] + "yy"
+	}
+
+	// Swap the claims, keeping the signature
+	forged := signJWT(hs256Header, map[string]interface{}{"sub": "admin"}, jwtSecret)
+	forged = forged[:strings.LastIndex(forged, ".")] + valid[strings.LastIndex(valid, "."):]
+
+	cases := map[string]struct {
+		token string
+		err   error
+	}{
+		"tampered signature": {tampered, ErrJWTSignature},
+		"forged claims":      {forged, ErrJWTSignature},
+		"wrong secret":       {signJWT(hs256Header, nil, []byte("other")), ErrJWTSignature},
+		"expired":            {signJWT(hs256Header, map[string]interface{}{"exp": now - 1}, jwtSecret), ErrJWTExpired},
+		"not yet valid":      {signJWT(hs256Header, map[string]interface{}{"nbf": now + 60}, jwtSecret), ErrJWTNotYetValid},
+		"alg none":           {signJWT(map[string]interface{}{"alg": "none"}, nil, jwtSecret), ErrJWTUnsupported},
+		"alg HS512":          {signJWT(map[string]interface{}{"alg": "HS512"}, nil, jwtSecret), ErrJWTUnsupported},
+		"no alg":             {signJWT(map[string]interface{}{}, nil, jwtSecret), ErrJWTUnsupported},
+		"string exp":         {signJWT(hs256Header, map[string]interface{}{"exp": "tomorrow"}, jwtSecret), ErrInvalidJWT},
+		"null nbf":           {signJWT(hs256Header, map[string]interface{}{"nbf": nil}, jwtSecret), ErrInvalidJWT},
+		"two segments":       {"eyJhbGciOiJIUzI1NiJ9.e30", ErrInvalidJWT},
+		"invalid base64":     {"!!!.e30.abc", ErrInvalidJWT},
+		"empty":              {"", ErrInvalidJWT},
+	}
+
+	for name, c := range cases {
+		if _, err := ParseJWTHS256(c.token, jwtSecret); err != c.err {
+			t.Errorf("Expected %v for %s, but got %v", c.err, name, err)
+		}
+	}
+}
+
+func TestParseJWTHS256Leeway(t *testing.T) {
+	now := time.Now().Unix()
+	expired := signJWT(hs256Header, map[string]interface{}{"exp": now - 10}, jwtSecret)
+	early := signJWT(hs256Header, map[string]interface{}{"nbf": now + 10}, jwtSecret)
+
+	for _, token := range []string{expired, early} {
+		if _, err := ParseJWTHS256Leeway(token, jwtSecret, time.Minute); err != nil {
+			t.Errorf("Expected no error within the leeway, but got %v", err)
+		}
+	}
+
+	if _, err := ParseJWTHS256Leeway(expired, jwtSecret, 5*time.Second); err != ErrJWTExpired {
+		t.Errorf("Expected ErrJWTExpired outside the leeway, but got %v", err)
+	}
+}
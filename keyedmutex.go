@@ -0,0 +1,102 @@
+package abutil
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyedMutex is a mutex per key: operations with the same key are
+// serialized, while different keys proceed concurrently. Entries of keys
+// that aren't locked or waited for are removed, so it doesn't grow with the
+// number of keys ever used. The zero value is ready to use.
+type KeyedMutex struct {
+	m     sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	// sem holds a value while locked
+	sem chan struct{}
+
+	// refs counts the holder and waiters
+	refs int
+}
+
+// Lock locks key, waiting until it's unlocked if necessary
+func (k *KeyedMutex) Lock(key string) {
+	k.acquire(key).sem <- struct{}{}
+}
+
+// TryLock locks key if it's unlocked and reports if it did
+func (k *KeyedMutex) TryLock(key string) bool {
+	l := k.acquire(key)
+
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+		k.release(key, l)
+		return false
+	}
+}
+
+// LockContext is like Lock, but stops waiting and returns the error of ctx
+// when it's done
+func (k *KeyedMutex) LockContext(ctx context.Context, key string) error {
+	l := k.acquire(key)
+
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		k.release(key, l)
+		return ctx.Err()
+	}
+}
+
+// Unlock unlocks key. Like with sync.Mutex, it panics if key isn't locked.
+func (k *KeyedMutex) Unlock(key string) {
+	k.m.Lock()
+	l, ok := k.locks[key]
+	k.m.Unlock()
+
+	if ok {
+		select {
+		case <-l.sem:
+			k.release(key, l)
+			return
+		default:
+		}
+	}
+
+	panic("abutil: unlock of unlocked key " + key)
+}
+
+// acquire returns the lock of key, counting a reference
+func (k *KeyedMutex) acquire(key string) *keyedLock {
+	k.m.Lock()
+	defer k.m.Unlock()
+
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{sem: make(chan struct{}, 1)}
+		k.locks[key] = l
+	}
+	l.refs++
+
+	return l
+}
+
+// release removes a reference, and the lock without references
+func (k *KeyedMutex) release(key string, l *keyedLock) {
+	k.m.Lock()
+	defer k.m.Unlock()
+
+	if l.refs--; l.refs == 0 {
+		delete(k.locks, key)
+	}
+}
@@ -0,0 +1,118 @@
+package abutil
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	var (
+		k       KeyedMutex
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		running = map[string]int{}
+		maxRun  = map[string]int{}
+	)
+
+	for i := 0; i < 20; i++ {
+		key := []string{"a", "b"}[i%2]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			k.Lock(key)
+			defer k.Unlock(key)
+
+			mu.Lock()
+			running[key]++
+			if running[key] > maxRun[key] {
+				maxRun[key] = running[key]
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running[key]--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxRun["a"] != 1 || maxRun["b"] != 1 {
+		t.Errorf("Expected at most one holder per key, but got %v", maxRun)
+	}
+
+	if len(k.locks) != 0 {
+		t.Errorf("Expected unused keys to be removed, but got %d", len(k.locks))
+	}
+}
+
+func TestKeyedMutexParallel(t *testing.T) {
+	var k KeyedMutex
+	k.Lock("a")
+
+	done := make(chan struct{})
+	go func() {
+		k.Lock("b")
+		k.Unlock("b")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expected a different key not to wait")
+	}
+
+	k.Unlock("a")
+}
+
+func TestKeyedMutexTryLock(t *testing.T) {
+	var k KeyedMutex
+
+	if !k.TryLock("a") {
+		t.Error("Expected TryLock to lock an unlocked key")
+	}
+
+	if k.TryLock("a") {
+		t.Error("Expected TryLock to fail for a locked key")
+	}
+
+	k.Unlock("a")
+	if len(k.locks) != 0 {
+		t.Errorf("Expected unused keys to be removed, but got %d", len(k.locks))
+	}
+}
+
+func TestKeyedMutexLockContext(t *testing.T) {
+	var k KeyedMutex
+	k.Lock("a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := k.LockContext(ctx, "a"); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, but got %v", err)
+	}
+
+	k.Unlock("a")
+	if err := k.LockContext(context.Background(), "a"); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+	k.Unlock("a")
+}
+
+func TestKeyedMutexUnlockUnlocked(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Unlock of an unlocked key to panic")
+		}
+	}()
+
+	var k KeyedMutex
+	k.Unlock("a")
+}
@@ -0,0 +1,75 @@
+package abutil
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PreferredLanguage returns the supported language the client prefers most
+// according to its Accept-Language header, or def if none matches. Tags
+// match exactly or by prefix (e.g. "en-US" matches a supported "en"), the
+// "*" wildcard matches the first supported language. Malformed entries are
+// ignored.
+func PreferredLanguage(r *http.Request, supported []string, def string) string {
+	type tag struct {
+		name string
+		q    float64
+	}
+
+	var tags []tag
+	for _, v := range SplitHeaderValues(r.Header.Get("Accept-Language")) {
+		params := strings.Split(v, ";")
+		name := strings.TrimSpace(params[0])
+		q := 1.0
+
+		// Only the q parameter matters, others are skipped
+		valid := true
+		for _, p := range params[1:] {
+			k, val, _ := strings.Cut(p, "=")
+			if !strings.EqualFold(strings.TrimSpace(k), "q") {
+				continue
+			}
+
+			var err error
+			q, err = strconv.ParseFloat(strings.TrimSpace(val), 64)
+			valid = err == nil && q >= 0 && q <= 1
+		}
+
+		if valid && name != "" && q > 0 {
+			tags = append(tags, tag{strings.ToLower(name), q})
+		}
+	}
+
+	// Keep the client's order for equal q-values
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	for _, t := range tags {
+		if t.name == "*" {
+			if len(supported) > 0 {
+				return supported[0]
+			}
+
+			continue
+		}
+
+		// Exact matches first, then prefixes in either direction
+		for _, s := range supported {
+			if strings.ToLower(s) == t.name {
+				return s
+			}
+		}
+
+		for _, s := range supported {
+			ls := strings.ToLower(s)
+			if strings.HasPrefix(t.name, ls+"-") || strings.HasPrefix(ls, t.name+"-") {
+				return s
+			}
+		}
+	}
+
+	return def
+}
@@ -0,0 +1,55 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPreferredLanguage(t *testing.T) {
+	supported := []string{"en", "de-DE", "fr"}
+
+	cases := map[string]string{
+		"":                             "en",
+		"fr":                           "fr",
+		"de-DE,en;q=0.5":               "de-DE",
+		"en;q=0.5,de-de":               "de-DE",
+		"en-US,en;q=0.9":               "en",
+		"de":                           "de-DE",
+		"es,fr;q=0.1":                  "fr",
+		"es":                           "xx",
+		"es,*;q=0.1":                   "en",
+		"fr;q=0,en;q=0.5":              "en",
+		"fr;q=abc,de;q=2,es;quality=1": "xx",
+		";;;,,fr":                      "fr",
+		"fr;q=0.8,de;q=0.8":            "fr",
+		"es,en;q=0.5;x=y":              "en",
+		"fr;x=y;q=0.1,de;q=0.2":        "de-DE",
+		"es,fr;level=1":                "fr",
+	}
+
+	for header, expected := range cases {
+		mockRequestContext(t, func(r *http.Request) {
+			r.Header.Set("Accept-Language", header)
+
+			def := "xx"
+			if header == "" {
+				def = "en"
+			}
+
+			if out := PreferredLanguage(r, supported, def); out != expected {
+				t.Errorf("Expected %s for %q, but got %s", expected, header, out)
+			}
+		})
+	}
+}
+
+func ExamplePreferredLanguage() {
+	mockRequestContext(nil, func(r *http.Request) {
+		r.Header.Set("Accept-Language", "de-CH,fr;q=0.8,en;q=0.5")
+
+		fmt.Println(PreferredLanguage(r, []string{"en", "de"}, "en"))
+	})
+
+	// Output: de
+}
@@ -0,0 +1,84 @@
+package abutil
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyTracker records durations and estimates their percentiles. It keeps
+// a uniform random sample (reservoir sampling) of a fixed size, so memory is
+// bounded no matter how many durations are observed. It's safe for
+// concurrent use.
+type LatencyTracker struct {
+	m       sync.Mutex
+	samples []time.Duration
+	size    int
+	count   int64
+	rnd     *rand.Rand
+}
+
+// NewLatencyTracker returns a LatencyTracker keeping up to size samples. A
+// few thousand give good estimates for p99.
+func NewLatencyTracker(size int) *LatencyTracker {
+	if size < 1 {
+		size = 1
+	}
+
+	return &LatencyTracker{
+		samples: make([]time.Duration, 0, size),
+		size:    size,
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Observe records a duration
+func (l *LatencyTracker) Observe(d time.Duration) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.count++
+	if len(l.samples) < l.size {
+		l.samples = append(l.samples, d)
+		return
+	}
+
+	// Replace a random sample with probability size/count, so every
+	// observation is equally likely to be in the sample
+	if i := l.rnd.Int63n(l.count); i < int64(l.size) {
+		l.samples[i] = d
+	}
+}
+
+// Count returns the number of observed durations
+func (l *LatencyTracker) Count() int64 {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return l.count
+}
+
+// Percentile returns the estimated q-th quantile (0 <= q <= 1) of the
+// observed durations, e.g. 0.99 for p99. It returns 0 if nothing was
+// observed.
+func (l *LatencyTracker) Percentile(q float64) time.Duration {
+	l.m.Lock()
+	s := append([]time.Duration(nil), l.samples...)
+	l.m.Unlock()
+
+	if len(s) == 0 {
+		return 0
+	}
+
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+
+	// Nearest-rank method
+	rank := int(math.Ceil(math.Max(0, math.Min(1, q))*float64(len(s)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return s[rank]
+}
@@ -0,0 +1,61 @@
+package abutil
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	l := NewLatencyTracker(1000)
+	if l.Percentile(0.5) != 0 {
+		t.Error("Expected 0 without observations")
+	}
+
+	for i := 1000; i > 0; i-- {
+		l.Observe(time.Duration(i) * time.Millisecond)
+	}
+
+	expected := map[float64]time.Duration{
+		0:    time.Millisecond,
+		0.5:  500 * time.Millisecond,
+		0.95: 950 * time.Millisecond,
+		0.99: 990 * time.Millisecond,
+		1:    time.Second,
+	}
+
+	for q, d := range expected {
+		if p := l.Percentile(q); p != d {
+			t.Errorf("Expected %s for %v, but got %s", d, q, p)
+		}
+	}
+}
+
+func TestLatencyTrackerBounded(t *testing.T) {
+	l := NewLatencyTracker(2000)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < 100000; i += 4 {
+				l.Observe(time.Duration(i) * time.Microsecond)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if l.Count() != 100000 || len(l.samples) != 2000 {
+		t.Errorf("Expected 100000 observations in 2000 samples, but got %d in %d",
+			l.Count(), len(l.samples))
+	}
+
+	// Uniform from 0 to 100ms, so each estimate should be within a few ms
+	for _, q := range []float64{0.5, 0.95, 0.99} {
+		expected := time.Duration(q * float64(100*time.Millisecond))
+		if p := l.Percentile(q); p < expected-5*time.Millisecond || p > expected+5*time.Millisecond {
+			t.Errorf("Expected about %s for %v, but got %s", expected, q, p)
+		}
+	}
+}
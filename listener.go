@@ -0,0 +1,153 @@
+package abutil
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// GracefulListener wraps a net.Listener and tracks the accepted connections,
+// so non-HTTP servers can shut down gracefully like GracefulServer: Stop
+// closes the listener and waits for the connections to be closed.
+type GracefulListener struct {
+	net.Listener
+
+	mu      sync.Mutex
+	stopped bool
+	conns   connTracker
+}
+
+// NewGracefulListener creates a new GracefulListener wrapping l
+func NewGracefulListener(l net.Listener) *GracefulListener {
+	return &GracefulListener{Listener: l}
+}
+
+// Accept waits for and returns the next connection, which is tracked until
+// it's closed. After Stop, it returns net.ErrClosed.
+func (g *GracefulListener) Accept() (net.Conn, error) {
+	c, err := g.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stopped {
+		c.Close()
+		return nil, net.ErrClosed
+	}
+
+	gc := &gracefulConn{Conn: c}
+	gc.release = g.conns.add(gc)
+
+	return gc, nil
+}
+
+// Stop closes the listener and waits for the accepted connections to be
+// closed. After the timeout, the remaining connections are closed and false
+// is returned. A timeout of 0 waits forever.
+func (g *GracefulListener) Stop(timeout time.Duration) bool {
+	g.mu.Lock()
+	g.stopped = true
+	g.mu.Unlock()
+
+	g.Listener.Close()
+
+	return g.conns.wait(timeout)
+}
+
+// gracefulConn is released from its GracefulListener when closed
+type gracefulConn struct {
+	net.Conn
+	release func()
+}
+
+func (c *gracefulConn) Close() error {
+	err := c.Conn.Close()
+	c.release()
+
+	return err
+}
+
+// connTracker tracks connections until they're released. The zero value is
+// ready to use.
+type connTracker struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+
+	// empty is closed when the last connection is released
+	empty chan struct{}
+}
+
+// add tracks c until the returned function is called, which is safe to call
+// multiple times
+func (t *connTracker) add(c net.Conn) (release func()) {
+	t.mu.Lock()
+	if t.conns == nil {
+		t.conns = make(map[net.Conn]struct{})
+	}
+	t.conns[c] = struct{}{}
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			delete(t.conns, c)
+			if len(t.conns) == 0 && t.empty != nil {
+				close(t.empty)
+				t.empty = nil
+			}
+		})
+	}
+}
+
+// closeAll closes the tracked connections
+func (t *connTracker) closeAll() {
+	t.mu.Lock()
+	conns := make([]net.Conn, 0, len(t.conns))
+	for c := range t.conns {
+		conns = append(conns, c)
+	}
+	t.mu.Unlock()
+
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// wait waits for all connections to be released. After the timeout, the
+// remaining ones are closed and false is returned. A timeout of 0 waits
+// forever.
+func (t *connTracker) wait(timeout time.Duration) bool {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		t.mu.Lock()
+		if len(t.conns) == 0 {
+			t.mu.Unlock()
+			return true
+		}
+
+		if t.empty == nil {
+			t.empty = make(chan struct{})
+		}
+		empty := t.empty
+		t.mu.Unlock()
+
+		select {
+		case <-empty:
+		case <-deadline:
+			t.closeAll()
+			return false
+		}
+	}
+}
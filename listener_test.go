@@ -0,0 +1,91 @@
+package abutil
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func gracefulListenerContext(t *testing.T, fn func(*GracefulListener, net.Conn, net.Conn)) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGracefulListener(l)
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	server, err := g.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fn(g, client, server)
+}
+
+func TestGracefulListenerDrain(t *testing.T) {
+	gracefulListenerContext(t, func(g *GracefulListener, client, server net.Conn) {
+		time.AfterFunc(20*time.Millisecond, func() {
+			server.Write([]byte("bye"))
+			server.Close()
+		})
+
+		start := time.Now()
+		if !g.Stop(time.Second) {
+			t.Error("Expected Stop to return true after the connection was closed")
+		}
+
+		if d := time.Since(start); d < 20*time.Millisecond {
+			t.Errorf("Expected Stop to wait for the connection, but returned after %s", d)
+		}
+
+		// The connection was usable until closed by the server
+		if b, _ := io.ReadAll(client); string(b) != "bye" {
+			t.Errorf("Expected bye, but got %q", b)
+		}
+
+		if _, err := g.Accept(); err == nil {
+			t.Error("Expected Accept to fail after Stop")
+		}
+	})
+}
+
+func TestGracefulListenerNoTimeout(t *testing.T) {
+	gracefulListenerContext(t, func(g *GracefulListener, client, server net.Conn) {
+		time.AfterFunc(20*time.Millisecond, func() {
+			server.Close()
+		})
+
+		// A timeout of 0 waits forever
+		start := time.Now()
+		if !g.Stop(0) {
+			t.Error("Expected Stop(0) to return true after the connection was closed")
+		}
+
+		if d := time.Since(start); d < 20*time.Millisecond {
+			t.Errorf("Expected Stop(0) to wait for the connection, but returned after %s", d)
+		}
+	})
+}
+
+func TestGracefulListenerTimeout(t *testing.T) {
+	gracefulListenerContext(t, func(g *GracefulListener, client, server net.Conn) {
+		if g.Stop(20 * time.Millisecond) {
+			t.Error("Expected Stop to return false after the timeout")
+		}
+
+		// The connection was force-closed
+		if _, err := server.Write([]byte("foo")); !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Expected net.ErrClosed, but got %v", err)
+		}
+
+		// Closing it again is fine
+		server.Close()
+	})
+}
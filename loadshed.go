@@ -0,0 +1,37 @@
+package abutil
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// LoadShed is a middleware that responds with 503 Service Unavailable and a
+// Retry-After of one second right away, once maxInflight requests are being
+// handled, instead of letting them queue up. Requests to the exempt paths
+// (e.g. health checks) are always handled and not counted.
+func LoadShed(maxInflight int, exempt ...string) func(http.Handler) http.Handler {
+	var inflight int64
+	exempted := make(map[string]bool, len(exempt))
+	for _, p := range exempt {
+		exempted[p] = true
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempted[r.URL.Path] {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			defer atomic.AddInt64(&inflight, -1)
+			if atomic.AddInt64(&inflight, 1) > int64(maxInflight) {
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable),
+					http.StatusServiceUnavailable)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestLoadShed(t *testing.T) {
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(2)
+
+	h := LoadShed(2, "/health")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			started.Done()
+			<-release
+		}
+	}))
+
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w := request("/"); w.Code != http.StatusOK {
+				t.Errorf("Expected 200 below the threshold, but got %d", w.Code)
+			}
+		}()
+	}
+	started.Wait()
+
+	// Flood past the threshold
+	for i := 0; i < 5; i++ {
+		w := request("/")
+		if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+			t.Errorf("Expected 503 with Retry-After, but got %d %v", w.Code, w.Header())
+		}
+	}
+
+	if w := request("/health"); w.Code != http.StatusOK {
+		t.Errorf("Expected exempt paths to pass, but got %d", w.Code)
+	}
+
+	close(release)
+	wg.Wait()
+
+	// Shed requests aren't counted once they're done
+	started.Add(1)
+	if w := request("/"); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 after the load is gone, but got %d", w.Code)
+	}
+}
@@ -0,0 +1,88 @@
+package abutil
+
+import (
+	"sync"
+	"time"
+)
+
+// LogSampler throttles repeated log messages: a message is logged on its
+// first occurrence and then at most once per interval, with the number of
+// suppressed occurrences since. Messages are identified by their format
+// string. It's safe for concurrent use.
+type LogSampler struct {
+	interval time.Duration
+	logf     func(format string, v ...interface{})
+
+	mu        sync.Mutex
+	entries   map[string]*logSample
+	lastSweep time.Time
+}
+
+type logSample struct {
+	last       time.Time
+	suppressed int
+}
+
+// NewLogSampler creates a new LogSampler logging with logf (e.g. log.Printf)
+func NewLogSampler(interval time.Duration, logf func(format string, v ...interface{})) *LogSampler {
+	return &LogSampler{
+		interval: interval,
+		logf:     logf,
+		entries:  map[string]*logSample{},
+	}
+}
+
+// Logf logs the message with logf, unless a message with the same format was
+// logged within the interval. If occurrences were suppressed, their number
+// is appended, like "(suppressed 42 times)".
+func (l *LogSampler) Logf(format string, v ...interface{}) {
+	if n, ok := l.sample(format, time.Now()); ok {
+		if n > 0 {
+			l.logf(format+" (suppressed %d times)", append(v, n)...)
+		} else {
+			l.logf(format, v...)
+		}
+	}
+}
+
+// sample reports if format should be logged and the number of suppressed
+// occurrences since the last time
+func (l *LogSampler) sample(format string, now time.Time) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	e, ok := l.entries[format]
+	if !ok {
+		l.entries[format] = &logSample{last: now}
+		return 0, true
+	}
+
+	if now.Sub(e.last) < l.interval {
+		e.suppressed++
+		return 0, false
+	}
+
+	n := e.suppressed
+	e.last = now
+	e.suppressed = 0
+
+	return n, true
+}
+
+// sweep removes entries not logged for two intervals, at most once per
+// interval. Their next occurrence would be logged anyway, only the count of
+// suppressed ones is lost.
+func (l *LogSampler) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.interval {
+		return
+	}
+	l.lastSweep = now
+
+	for format, e := range l.entries {
+		if now.Sub(e.last) >= 2*l.interval {
+			delete(l.entries, format)
+		}
+	}
+}
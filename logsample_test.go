@@ -0,0 +1,68 @@
+package abutil
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogSampler(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		logs []string
+	)
+	l := NewLogSampler(time.Hour, func(format string, v ...interface{}) {
+		mu.Lock()
+		logs = append(logs, fmt.Sprintf(format, v...))
+		mu.Unlock()
+	})
+
+	Parallel(10, func() {
+		l.Logf("connection failed: %s", "timeout")
+	})
+	l.Logf("other error")
+
+	if len(logs) != 2 || logs[0] != "connection failed: timeout" ||
+		logs[1] != "other error" {
+		t.Errorf("Expected each message to be logged once, but got %q", logs)
+	}
+}
+
+func TestLogSamplerSample(t *testing.T) {
+	l := NewLogSampler(time.Minute, nil)
+	now := time.Now()
+
+	if n, ok := l.sample("foo", now); !ok || n != 0 {
+		t.Errorf("Expected the first occurrence to be logged, but got (%d, %t)", n, ok)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if _, ok := l.sample("foo", now.Add(time.Duration(i)*time.Second)); ok {
+			t.Error("Expected the occurrence within the interval to be suppressed")
+		}
+	}
+
+	if n, ok := l.sample("foo", now.Add(time.Minute)); !ok || n != 3 {
+		t.Errorf("Expected (3, true) after the interval, but got (%d, %t)", n, ok)
+	}
+
+	// Stale entries are removed
+	l.sample("bar", now.Add(2*time.Minute))
+	l.sample("baz", now.Add(4*time.Minute))
+	if len(l.entries) != 1 {
+		t.Errorf("Expected 1 entry after the sweep, but got %d", len(l.entries))
+	}
+}
+
+func ExampleLogSampler() {
+	l := NewLogSampler(time.Second, func(format string, v ...interface{}) {
+		fmt.Printf(format+"\n", v...)
+	})
+
+	for i := 0; i < 100; i++ {
+		l.Logf("retrying %s", "db")
+	}
+
+	// Output: retrying db
+}
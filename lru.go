@@ -0,0 +1,105 @@
+package abutil
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a concurrency-safe cache holding at most a fixed number of entries.
+// When it's full, adding an entry evicts the least recently used one.
+type LRU[K comparable, V any] struct {
+	m       sync.Mutex
+	cap     int
+	ll      *list.List
+	items   map[K]*list.Element
+	onEvict func(K, V)
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewLRU creates a new LRU with the given capacity (at least 1). If onEvict
+// isn't nil, it's called with every evicted entry, e.g. to release resources.
+func NewLRU[K comparable, V any](capacity int, onEvict func(K, V)) *LRU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &LRU[K, V]{
+		cap:     capacity,
+		ll:      list.New(),
+		items:   make(map[K]*list.Element),
+		onEvict: onEvict,
+	}
+}
+
+// Get returns the value for the key and marks it as recently used
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*lruEntry[K, V]).value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Add adds or updates the value for the key and marks it as recently used.
+// It returns true if another entry was evicted to make room.
+func (c *LRU[K, V]) Add(key K, value V) bool {
+	c.m.Lock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*lruEntry[K, V]).value = value
+		c.m.Unlock()
+		return false
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry[K, V]{key, value})
+	if c.ll.Len() <= c.cap {
+		c.m.Unlock()
+		return false
+	}
+
+	oldest := c.ll.Back()
+	c.ll.Remove(oldest)
+	entry := oldest.Value.(*lruEntry[K, V])
+	delete(c.items, entry.key)
+	c.m.Unlock()
+
+	// Call it without holding the lock, so it may use the cache
+	if c.onEvict != nil {
+		c.onEvict(entry.key, entry.value)
+	}
+
+	return true
+}
+
+// Remove removes the key from the cache without calling onEvict and returns
+// if it was present
+func (c *LRU[K, V]) Remove(key K) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	e, ok := c.items[key]
+	if ok {
+		c.ll.Remove(e)
+		delete(c.items, key)
+	}
+
+	return ok
+}
+
+// Len returns the number of entries in the cache
+func (c *LRU[K, V]) Len() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.ll.Len()
+}
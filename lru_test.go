@@ -0,0 +1,80 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	var evicted []string
+	c := NewLRU(2, func(k string, v int) {
+		evicted = append(evicted, fmt.Sprintf("%s=%d", k, v))
+	})
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	// a is now more recently used than b
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Expected (1, true), but got (%d, %v)", v, ok)
+	}
+
+	if !c.Add("c", 3) {
+		t.Error("Expected Add to evict an entry")
+	}
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+
+	// Updating doesn't evict, but marks as used
+	if c.Add("a", 10) {
+		t.Error("Expected updating not to evict")
+	}
+
+	c.Add("d", 4)
+
+	if fmt.Sprint(evicted) != "[b=2 c=3]" {
+		t.Errorf("Expected evictions [b=2 c=3], but got %v", evicted)
+	}
+
+	if v, _ := c.Get("a"); v != 10 {
+		t.Errorf("Expected a to be 10, but got %d", v)
+	}
+
+	if c.Len() != 2 {
+		t.Errorf("Expected length 2, but got %d", c.Len())
+	}
+
+	if !c.Remove("a") || c.Remove("a") || c.Len() != 1 {
+		t.Error("Expected Remove to remove a once")
+	}
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	c := NewLRU[int, int](10, nil)
+
+	Parallel(8, func() {
+		for i := 0; i < 100; i++ {
+			c.Add(i, i)
+			c.Get(i - 1)
+		}
+	})
+
+	if c.Len() != 10 {
+		t.Errorf("Expected length 10, but got %d", c.Len())
+	}
+}
+
+func ExampleLRU() {
+	c := NewLRU(2, func(k string, v int) {
+		fmt.Println("Evicted", k)
+	})
+
+	c.Add("foo", 1)
+	c.Add("bar", 2)
+	c.Get("foo")
+	c.Add("baz", 3)
+
+	// Output: Evicted bar
+}
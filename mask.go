@@ -0,0 +1,51 @@
+package abutil
+
+import (
+	"strings"
+)
+
+// secretMask replaces the hidden part of a masked string. It has a fixed
+// length, so masked strings don't reveal the length of the secret.
+const secretMask = "****"
+
+// MaskSecret masks s for logging, revealing only the first and last two
+// characters (e.g. "ab****yz"), see MaskSecretN
+func MaskSecret(s string) string {
+	return MaskSecretN(s, 2, 2)
+}
+
+// MaskSecretN masks s for logging, revealing only the first prefix and the
+// last suffix characters. Strings too short to hide at least half of them
+// are masked completely.
+func MaskSecretN(s string, prefix, suffix int) string {
+	if prefix < 0 {
+		prefix = 0
+	}
+
+	if suffix < 0 {
+		suffix = 0
+	}
+
+	r := []rune(s)
+	if len(r) <= 2*(prefix+suffix) {
+		return secretMask
+	}
+
+	return string(r[:prefix]) + secretMask + string(r[len(r)-suffix:])
+}
+
+// MaskEmail masks the local part of an email address but keeps the domain,
+// e.g. "j****@example.com". Strings without an @ are masked with MaskSecret.
+func MaskEmail(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return MaskSecret(email)
+	}
+
+	local := []rune(email[:i])
+	if len(local) <= 2 {
+		return secretMask + email[i:]
+	}
+
+	return string(local[:1]) + secretMask + email[i:]
+}
@@ -0,0 +1,73 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMaskSecret(t *testing.T) {
+	cases := []struct {
+		in, expected string
+	}{
+		{"", "****"},
+		{"abc", "****"},
+		{"abcdefgh", "****"},
+		{"abcdefghi", "ab****hi"},
+		{"sk_live_1234567890", "sk****90"},
+		{"äöüßäöüßä", "äö****ßä"},
+	}
+
+	for _, c := range cases {
+		if out := MaskSecret(c.in); out != c.expected {
+			t.Errorf("Expected %q for %q, but got %q", c.expected, c.in, out)
+		}
+	}
+}
+
+func TestMaskSecretN(t *testing.T) {
+	cases := []struct {
+		in             string
+		prefix, suffix int
+		expected       string
+	}{
+		{"sk_live_1234567890", 8, 0, "sk_live_****"},
+		{"sk_live_1234567890", 0, 4, "****7890"},
+		{"sk_live_1234567890", 0, 0, "****"},
+		{"sk_live_1234567890", -1, -1, "****"},
+		{"short", 3, 3, "****"},
+	}
+
+	for _, c := range cases {
+		if out := MaskSecretN(c.in, c.prefix, c.suffix); out != c.expected {
+			t.Errorf("Expected %q for %q (%d, %d), but got %q", c.expected,
+				c.in, c.prefix, c.suffix, out)
+		}
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	cases := []struct {
+		in, expected string
+	}{
+		{"john.doe@example.com", "j****@example.com"},
+		{"jd@example.com", "****@example.com"},
+		{"@example.com", "****@example.com"},
+		{"a@b@example.com", "a****@example.com"},
+		{"not-an-email", "no****il"},
+	}
+
+	for _, c := range cases {
+		if out := MaskEmail(c.in); out != c.expected {
+			t.Errorf("Expected %q for %q, but got %q", c.expected, c.in, out)
+		}
+	}
+}
+
+func ExampleMaskSecret() {
+	fmt.Println(MaskSecret("sk_live_1234567890"))
+	fmt.Println(MaskEmail("john.doe@example.com"))
+
+	// Output:
+	// sk****90
+	// j****@example.com
+}
@@ -0,0 +1,47 @@
+package abutil
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MethodHandler returns a handler dispatching requests by method to the given
+// handlers. HEAD falls back to the GET handler and OPTIONS is answered with
+// a 204 and the Allow header, unless there are handlers for them. Other
+// methods get a 405 with the Allow header.
+func MethodHandler(handlers map[string]http.Handler) http.Handler {
+	hs := make(map[string]http.Handler, len(handlers))
+	for m, h := range handlers {
+		hs[strings.ToUpper(m)] = h
+	}
+
+	if _, ok := hs[http.MethodHead]; !ok && hs[http.MethodGet] != nil {
+		hs[http.MethodHead] = hs[http.MethodGet]
+	}
+
+	methods := []string{http.MethodOptions}
+	for m := range hs {
+		if m != http.MethodOptions {
+			methods = append(methods, m)
+		}
+	}
+	sort.Strings(methods)
+	allow := strings.Join(methods, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := hs[r.Method]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", allow)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+	})
+}
@@ -0,0 +1,89 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func methodHandlerContext(h http.Handler, method string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest(method, "http://some.url/users", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func respondWith(s string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(s))
+	})
+}
+
+func TestMethodHandler(t *testing.T) {
+	h := MethodHandler(map[string]http.Handler{
+		"GET":  respondWith("get"),
+		"post": respondWith("post"),
+	})
+
+	for method, body := range map[string]string{
+		"GET":  "get",
+		"POST": "post",
+		"HEAD": "get",
+	} {
+		w := methodHandlerContext(h, method)
+		if w.Code != http.StatusOK || w.Body.String() != body {
+			t.Errorf("Expected 200 with %s for %s, but got %d with %s", body,
+				method, w.Code, w.Body.String())
+		}
+	}
+
+	allow := "GET, HEAD, OPTIONS, POST"
+
+	w := methodHandlerContext(h, "DELETE")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, but got %d", http.StatusMethodNotAllowed,
+			w.Code)
+	}
+
+	if w.Header().Get("Allow") != allow {
+		t.Errorf("Expected Allow %q, but got %q", allow, w.Header().Get("Allow"))
+	}
+
+	w = methodHandlerContext(h, "OPTIONS")
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != allow {
+		t.Errorf("Expected 204 with Allow %q, but got %d with %q", allow, w.Code,
+			w.Header().Get("Allow"))
+	}
+}
+
+func TestMethodHandlerCustom(t *testing.T) {
+	h := MethodHandler(map[string]http.Handler{
+		"POST":    respondWith("post"),
+		"OPTIONS": respondWith("options"),
+	})
+
+	if w := methodHandlerContext(h, "OPTIONS"); w.Body.String() != "options" {
+		t.Errorf("Expected custom OPTIONS handler, but got %q", w.Body.String())
+	}
+
+	w := methodHandlerContext(h, "HEAD")
+	if w.Code != http.StatusMethodNotAllowed ||
+		w.Header().Get("Allow") != "OPTIONS, POST" {
+		t.Errorf("Expected 405 with Allow %q, but got %d with %q",
+			"OPTIONS, POST", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func ExampleMethodHandler() {
+	h := MethodHandler(map[string]http.Handler{
+		"GET":  respondWith("list users"),
+		"POST": respondWith("create user"),
+	})
+
+	w := methodHandlerContext(h, "DELETE")
+	fmt.Println(w.Code, w.Header().Get("Allow"))
+
+	// Output: 405 GET, HEAD, OPTIONS, POST
+}
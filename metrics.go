@@ -0,0 +1,50 @@
+package abutil
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives the request metrics collected by the Metrics
+// middleware. Implement it to plug in Prometheus, statsd or whatever you use.
+type MetricsRecorder interface {
+	// IncRequest is called after each request
+	IncRequest(method, path string, status int)
+
+	// ObserveLatency is called after each request with its duration
+	ObserveLatency(method, path string, status int, d time.Duration)
+
+	// AddInflight is called when a request starts
+	AddInflight(method, path string)
+
+	// DoneInflight is called when a request is finished
+	DoneInflight(method, path string)
+}
+
+// Metrics returns a middleware that reports each request to the given
+// MetricsRecorder. The label function maps a request to the path label, use
+// it to avoid a label per id (e.g. "/users/123" to "/users/:id"). If it's nil,
+// the URL path is used.
+func Metrics(rec MetricsRecorder, label func(*http.Request) string) func(http.Handler) http.Handler {
+	if label == nil {
+		label = func(r *http.Request) string {
+			return r.URL.Path
+		}
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := label(r)
+			start := time.Now()
+
+			rec.AddInflight(r.Method, path)
+			defer rec.DoneInflight(r.Method, path)
+
+			sw := NewStatusWriter(w)
+			h.ServeHTTP(sw, r)
+
+			rec.IncRequest(r.Method, path, sw.Status())
+			rec.ObserveLatency(r.Method, path, sw.Status(), time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeMetricsRecorder struct {
+	m sync.Mutex
+
+	requests  []string
+	latencies []time.Duration
+	inflight  int
+	maxFlight int
+}
+
+func (f *fakeMetricsRecorder) IncRequest(method, path string, status int) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.requests = append(f.requests, fmt.Sprintf("%s %s %d", method, path,
+		status))
+}
+
+func (f *fakeMetricsRecorder) ObserveLatency(method, path string, status int,
+	d time.Duration) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.latencies = append(f.latencies, d)
+}
+
+func (f *fakeMetricsRecorder) AddInflight(method, path string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.inflight++
+	if f.inflight > f.maxFlight {
+		f.maxFlight = f.inflight
+	}
+}
+
+func (f *fakeMetricsRecorder) DoneInflight(method, path string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.inflight--
+}
+
+func TestMetrics(t *testing.T) {
+	rec := &fakeMetricsRecorder{}
+	label := func(r *http.Request) string {
+		if strings.HasPrefix(r.URL.Path, "/users/") {
+			return "/users/:id"
+		}
+
+		return r.URL.Path
+	}
+
+	h := Metrics(rec, label)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				http.NotFound(w, r)
+				return
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			w.Write([]byte("Foobar"))
+		}))
+
+	for _, p := range []string{"/users/123", "/missing"} {
+		r, _ := http.NewRequest("GET", "http://some.url"+p, nil)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	expected := []string{"GET /users/:id 200", "GET /missing 404"}
+	if fmt.Sprint(rec.requests) != fmt.Sprint(expected) {
+		t.Errorf("Expected requests %v, but got %v", expected, rec.requests)
+	}
+
+	if len(rec.latencies) != 2 || rec.latencies[0] < 5*time.Millisecond {
+		t.Errorf("Expected two latencies, the first >= 5ms, but got %v",
+			rec.latencies)
+	}
+
+	if rec.inflight != 0 || rec.maxFlight != 1 {
+		t.Errorf("Expected inflight 0 and max 1, but got %d and %d",
+			rec.inflight, rec.maxFlight)
+	}
+
+	// Without label function
+	rec = &fakeMetricsRecorder{}
+	h = Metrics(rec, nil)(http.NotFoundHandler())
+	r, _ := http.NewRequest("POST", "http://some.url/users/123", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	expected = []string{"POST /users/123 404"}
+	if fmt.Sprint(rec.requests) != fmt.Sprint(expected) {
+		t.Errorf("Expected requests %v, but got %v", expected, rec.requests)
+	}
+}
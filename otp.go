@@ -0,0 +1,101 @@
+package abutil
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrInvalidDigits is returned by GenerateOTP for an unsupported number of
+// digits
+var ErrInvalidDigits = errors.New("abutil: digits must be between 1 and 18")
+
+// GenerateOTP returns a random numeric code with the given number of digits
+// (zero-padded), e.g. for codes sent via email or SMS
+func GenerateOTP(digits int) (string, error) {
+	if digits < 1 || digits > 18 {
+		return "", ErrInvalidDigits
+	}
+
+	// rand.Int is uniform, so there is no modulo bias
+	n, err := rand.Int(rand.Reader, big.NewInt(pow10(digits)))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", digits, n.Int64()), nil
+}
+
+// GenerateTOTP returns the time-based one-time password (RFC 6238, HMAC-SHA1)
+// for the given time. period is the step in seconds (30 if <= 0) and digits
+// the code length (6 if <= 0, at most 10).
+func GenerateTOTP(secret []byte, t time.Time, period, digits int) string {
+	period, digits = totpDefaults(period, digits)
+
+	return hotp(secret, uint64(t.Unix()/int64(period)), digits)
+}
+
+// ValidateTOTP reports if code is the TOTP for t or for up to window periods
+// before or after it, to allow for clock skew. All candidates are compared in
+// constant time.
+func ValidateTOTP(secret []byte, code string, t time.Time, period, digits, window int) bool {
+	period, digits = totpDefaults(period, digits)
+	counter := t.Unix() / int64(period)
+
+	valid := 0
+	for i := -window; i <= window; i++ {
+		c := counter + int64(i)
+		if c < 0 {
+			continue
+		}
+
+		valid |= subtle.ConstantTimeCompare([]byte(hotp(secret, uint64(c), digits)), []byte(code))
+	}
+
+	return valid == 1
+}
+
+func totpDefaults(period, digits int) (int, int) {
+	if period <= 0 {
+		period = 30
+	}
+
+	if digits <= 0 {
+		digits = 6
+	} else if digits > 10 {
+		digits = 10
+	}
+
+	return period, digits
+}
+
+// hotp implements RFC 4226
+func hotp(secret []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0xf
+	code := int64(binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff)
+
+	return fmt.Sprintf("%0*d", digits, code%pow10(digits))
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+
+	return p
+}
@@ -0,0 +1,95 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGenerateOTP(t *testing.T) {
+	for _, digits := range []int{1, 6, 8, 18} {
+		code, err := GenerateOTP(digits)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(code) != digits {
+			t.Errorf("Expected %d digits, but got %q", digits, code)
+		}
+
+		for _, c := range code {
+			if c < '0' || c > '9' {
+				t.Errorf("Expected only digits, but got %q", code)
+			}
+		}
+	}
+
+	for _, digits := range []int{0, -1, 19} {
+		if _, err := GenerateOTP(digits); err != ErrInvalidDigits {
+			t.Errorf("Expected ErrInvalidDigits for %d, but got %v", digits, err)
+		}
+	}
+}
+
+// Test vectors from RFC 6238, Appendix B (SHA1)
+var totpVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "94287082"},
+	{1111111109, "07081804"},
+	{1111111111, "14050471"},
+	{1234567890, "89005924"},
+	{2000000000, "69279037"},
+	{20000000000, "65353130"},
+}
+
+var totpSecret = []byte("12345678901234567890")
+
+func TestGenerateTOTP(t *testing.T) {
+	for _, v := range totpVectors {
+		code := GenerateTOTP(totpSecret, time.Unix(v.unix, 0), 30, 8)
+		if code != v.code {
+			t.Errorf("Expected %s at %d, but got %s", v.code, v.unix, code)
+		}
+	}
+
+	// Defaults to 30 seconds and 6 digits
+	if code := GenerateTOTP(totpSecret, time.Unix(59, 0), 0, 0); code != "287082" {
+		t.Errorf("Expected 287082, but got %s", code)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code := GenerateTOTP(totpSecret, now, 30, 6)
+
+	if !ValidateTOTP(totpSecret, code, now, 30, 6, 0) {
+		t.Error("Expected the current code to be valid")
+	}
+
+	if !ValidateTOTP(totpSecret, code, now.Add(30*time.Second), 30, 6, 1) {
+		t.Error("Expected the previous code to be valid within the window")
+	}
+
+	if ValidateTOTP(totpSecret, code, now.Add(30*time.Second), 30, 6, 0) {
+		t.Error("Expected the previous code to be invalid without a window")
+	}
+
+	if ValidateTOTP(totpSecret, code, now.Add(90*time.Second), 30, 6, 1) {
+		t.Error("Expected an old code to be invalid")
+	}
+
+	for _, invalid := range []string{"", "000000", code + "0", code[:5]} {
+		if ValidateTOTP(totpSecret, invalid, now, 30, 6, 1) {
+			t.Errorf("Expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func ExampleGenerateTOTP() {
+	secret := []byte("12345678901234567890")
+	fmt.Println(GenerateTOTP(secret, time.Unix(59, 0), 30, 8))
+
+	// Output: 94287082
+}
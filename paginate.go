@@ -0,0 +1,103 @@
+package abutil
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// PageMeta is the pagination metadata of a Page
+type PageMeta struct {
+	Total   int    `json:"total"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	First   string `json:"first"`
+	Last    string `json:"last"`
+	Prev    string `json:"prev,omitempty"`
+	Next    string `json:"next,omitempty"`
+}
+
+// Page is a JSON API response envelope for paginated lists
+type Page struct {
+	Data interface{} `json:"data"`
+	Meta PageMeta    `json:"meta"`
+}
+
+// Paginate wraps data in a Page. The links are built from u (usually
+// r.URL) by setting the page and per_page query parameters. page is clamped
+// to the valid range and perPage to at least 1; Prev and Next are empty on
+// the first and last page.
+func Paginate(data interface{}, page, perPage, total int, u *url.URL) Page {
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	if total < 0 {
+		total = 0
+	}
+
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+
+	if page < 1 {
+		page = 1
+	} else if page > last {
+		page = last
+	}
+
+	link := func(p int) string {
+		l := *u
+		q := l.Query()
+		q.Set("page", strconv.Itoa(p))
+		q.Set("per_page", strconv.Itoa(perPage))
+		l.RawQuery = q.Encode()
+
+		return l.String()
+	}
+
+	meta := PageMeta{
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+		First:   link(1),
+		Last:    link(last),
+	}
+
+	if page > 1 {
+		meta.Prev = link(page - 1)
+	}
+
+	if page < last {
+		meta.Next = link(page + 1)
+	}
+
+	return Page{Data: data, Meta: meta}
+}
+
+// PaginateSlice returns the window of s for the given page and the length of
+// s as total, which can be passed on to Paginate. Like there, page is at
+// least 1 and perPage at least 1, while pages after the last one are empty.
+// The window shares the backing array of s.
+func PaginateSlice[T any](s []T, page, perPage int) (window []T, total int) {
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	// Compare before multiplying, so huge pages can't overflow
+	if page-1 >= (len(s)+perPage-1)/perPage {
+		return []T{}, len(s)
+	}
+
+	start := (page - 1) * perPage
+	end := len(s)
+	if len(s)-start > perPage {
+		end = start + perPage
+	}
+
+	return s[start:end:end], len(s)
+}
@@ -0,0 +1,106 @@
+package abutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	u, _ := url.Parse("/users?sort=name&page=3")
+	link := func(p int) string {
+		return fmt.Sprintf("/users?page=%d&per_page=10&sort=name", p)
+	}
+
+	tests := []struct {
+		page, total int
+		meta        PageMeta
+	}{
+		// Middle page
+		{3, 95, PageMeta{95, 3, 10, link(1), link(10), link(2), link(4)}},
+		// First and last page
+		{1, 95, PageMeta{95, 1, 10, link(1), link(10), "", link(2)}},
+		{10, 95, PageMeta{95, 10, 10, link(1), link(10), link(9), ""}},
+		// Clamped pages
+		{0, 95, PageMeta{95, 1, 10, link(1), link(10), "", link(2)}},
+		{42, 95, PageMeta{95, 10, 10, link(1), link(10), link(9), ""}},
+		// Empty list
+		{1, 0, PageMeta{0, 1, 10, link(1), link(1), "", ""}},
+	}
+
+	for _, test := range tests {
+		p := Paginate([]int{1}, test.page, 10, test.total, u)
+		if p.Meta != test.meta {
+			t.Errorf("Expected %+v for page %d of %d, but got %+v", test.meta,
+				test.page, test.total, p.Meta)
+		}
+	}
+
+	if p := Paginate(nil, 1, 0, 5, u); p.Meta.PerPage != 1 || p.Meta.Last != "/users?page=5&per_page=1&sort=name" {
+		t.Errorf("Expected per_page to be clamped to 1, but got %+v", p.Meta)
+	}
+
+	if u.String() != "/users?sort=name&page=3" {
+		t.Errorf("Expected the URL to be unchanged, but got %s", u)
+	}
+}
+
+func TestPaginateSlice(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6, 7}
+
+	cases := []struct {
+		page, perPage int
+		expected      []int
+	}{
+		{1, 3, []int{1, 2, 3}},
+		{2, 3, []int{4, 5, 6}},
+		{3, 3, []int{7}},
+		{4, 3, []int{}},
+		{0, 3, []int{1, 2, 3}},
+		{-1, 3, []int{1, 2, 3}},
+		{2, 0, []int{2}},
+		{1, 10, []int{1, 2, 3, 4, 5, 6, 7}},
+		{math.MaxInt, math.MaxInt, []int{}},
+		{2, math.MaxInt, []int{}},
+	}
+
+	for _, c := range cases {
+		window, total := PaginateSlice(s, c.page, c.perPage)
+		if fmt.Sprint(window) != fmt.Sprint(c.expected) || window == nil || total != 7 {
+			t.Errorf("Expected (%v, 7) for page %d of %d, but got (%v, %d)",
+				c.expected, c.page, c.perPage, window, total)
+		}
+	}
+
+	// Appending to the window doesn't change s
+	window, _ := PaginateSlice(s, 1, 3)
+	_ = append(window, 42)
+	if s[3] != 4 {
+		t.Error("Expected append not to change s")
+	}
+
+	if window, total := PaginateSlice([]int(nil), 1, 10); len(window) != 0 || total != 0 {
+		t.Errorf("Expected an empty window, but got (%v, %d)", window, total)
+	}
+}
+
+func ExamplePaginate() {
+	u, _ := url.Parse("/users?page=2")
+	p := Paginate([]string{"alice", "bob"}, 2, 2, 5, u)
+
+	b, _ := json.MarshalIndent(p.Meta, "", "  ")
+	fmt.Println(string(b))
+
+	// Output:
+	// {
+	//   "total": 5,
+	//   "page": 2,
+	//   "per_page": 2,
+	//   "first": "/users?page=1\u0026per_page=2",
+	//   "last": "/users?page=3\u0026per_page=2",
+	//   "prev": "/users?page=1\u0026per_page=2",
+	//   "next": "/users?page=3\u0026per_page=2"
+	// }
+}
@@ -0,0 +1,195 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseBool parses "1", "t", "true", "y", "yes" and "on" as true and "0", "f",
+// "false", "n", "no" and "off" as false, case-insensitively. Surrounding
+// whitespace is trimmed, so an empty or blank string is an error.
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("abutil: invalid boolean %q", s)
+}
+
+// ParseBoolDefault is like ParseBool, but returns def if s is invalid
+func ParseBoolDefault(s string, def bool) bool {
+	b, err := ParseBool(s)
+	if err != nil {
+		return def
+	}
+
+	return b
+}
+
+// ParseIntParam parses a path or query parameter as a decimal int64. Unlike
+// strconv.ParseInt it rejects a leading +, surrounding whitespace and leading
+// zeros, so each number has exactly one valid form. Errors are *HTTPError
+// with status 400, so they can be passed to WriteHTTPError.
+func ParseIntParam(s string) (int64, error) {
+	if !isParamNumber(strings.TrimPrefix(s, "-")) {
+		return 0, paramError(s, "is not an integer")
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, paramError(s, "is out of range")
+	}
+
+	return n, nil
+}
+
+// ParseUintParam is like ParseIntParam, but parses an uint64 and rejects
+// negative values
+func ParseUintParam(s string) (uint64, error) {
+	if strings.HasPrefix(s, "-") && isParamNumber(s[1:]) {
+		return 0, paramError(s, "must not be negative")
+	}
+
+	if !isParamNumber(s) {
+		return 0, paramError(s, "is not an integer")
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, paramError(s, "is out of range")
+	}
+
+	return n, nil
+}
+
+// ParsePositiveIntParam is like ParseIntParam, but rejects zero and negative
+// values, which is what you want for IDs
+func ParsePositiveIntParam(s string) (int64, error) {
+	n, err := ParseIntParam(s)
+	if err == nil && n <= 0 {
+		return 0, paramError(s, "must be positive")
+	}
+
+	return n, err
+}
+
+// isParamNumber reports if s consists of digits without leading zeros
+func isParamNumber(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func paramError(s, reason string) error {
+	return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%q %s", s, reason))
+}
+
+// IntRange is an inclusive range of integers, as parsed by
+// ParseIntRangeSpec
+type IntRange struct {
+	Start, End int
+}
+
+// ParseIntRangeSpec parses a comma-separated list of non-negative integers
+// and ranges, like "1-5,8,11-13", into sorted ranges. Overlapping and
+// adjacent ranges are merged. Ranges with a start after their end, empty and
+// non-numeric parts are an error, an empty spec results in nil. Unlike
+// ParseIntRanges, the ranges aren't expanded, so they can be huge.
+func ParseIntRangeSpec(s string) ([]IntRange, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var ranges []IntRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		start, end, isRange := strings.Cut(part, "-")
+		if !isRange {
+			end = start
+		}
+
+		a, errA := parseRangeBound(start)
+		b, errB := parseRangeBound(end)
+		if errA != nil || errB != nil {
+			return nil, fmt.Errorf("abutil: invalid range %q", part)
+		}
+
+		if a > b {
+			return nil, fmt.Errorf("abutil: invalid range %q: start after end", part)
+		}
+
+		ranges = append(ranges, IntRange{a, b})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		// Start is non-negative, so Start-1 can't overflow unlike End+1
+		if r.Start-1 <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged, nil
+}
+
+// ParseIntRanges is like ParseIntRangeSpec, but returns all integers of the
+// ranges, sorted and without duplicates, e.g. [1 2 3 5] for "1-3,2,5". It
+// returns an error if there are more than limit integers.
+func ParseIntRanges(s string, limit int) ([]int, error) {
+	ranges, err := ParseIntRangeSpec(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var ints []int
+	for _, r := range ranges {
+		if r.End-r.Start >= limit-len(ints) {
+			return nil, fmt.Errorf("abutil: more than %d integers in %q", limit, s)
+		}
+
+		// Break before incrementing, End may be math.MaxInt
+		for i := r.Start; ; i++ {
+			ints = append(ints, i)
+			if i == r.End {
+				break
+			}
+		}
+	}
+
+	return ints, nil
+}
+
+// parseRangeBound parses a trimmed, non-negative integer
+func parseRangeBound(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, strconv.ErrSyntax
+		}
+	}
+
+	return strconv.Atoi(s)
+}
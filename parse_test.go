@@ -0,0 +1,178 @@
+package abutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestParseBool(t *testing.T) {
+	for _, s := range []string{"1", "t", "T", "true", "TRUE", "True", "y",
+		"yes", "YES", "on", "On", " true\n"} {
+		if b, err := ParseBool(s); err != nil || !b {
+			t.Errorf("Expected (true, <nil>) for %q, but got (%v, %v)", s, b, err)
+		}
+	}
+
+	for _, s := range []string{"0", "f", "F", "false", "FALSE", "n", "no",
+		"No", "off", "OFF", " off "} {
+		if b, err := ParseBool(s); err != nil || b {
+			t.Errorf("Expected (false, <nil>) for %q, but got (%v, %v)", s, b,
+				err)
+		}
+	}
+
+	for _, s := range []string{"", "  ", "2", "yess", "enabled"} {
+		if _, err := ParseBool(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestParseBoolDefault(t *testing.T) {
+	if !ParseBoolDefault("", true) {
+		t.Error("Expected the default for an empty string")
+	}
+
+	if ParseBoolDefault("nope", false) {
+		t.Error("Expected the default for an invalid string")
+	}
+
+	if ParseBoolDefault("off", true) {
+		t.Error("Expected off to be false")
+	}
+}
+
+func ExampleParseBool() {
+	b, err := ParseBool("Yes")
+	fmt.Println(b, err)
+
+	// Output: true <nil>
+}
+
+func TestParseIntParam(t *testing.T) {
+	cases := []struct {
+		in  string
+		out int64
+	}{
+		{"0", 0},
+		{"42", 42},
+		{"-42", -42},
+		{"9223372036854775807", 9223372036854775807},
+		{"-9223372036854775808", -9223372036854775808},
+	}
+
+	for _, c := range cases {
+		if n, err := ParseIntParam(c.in); err != nil || n != c.out {
+			t.Errorf("Expected (%d, <nil>) for %q, but got (%d, %v)", c.out, c.in,
+				n, err)
+		}
+	}
+
+	for _, s := range []string{"", "-", "abc", "12a", "+1", " 1", "1.5", "007",
+		"-01", "9223372036854775808"} {
+		if _, err := ParseIntParam(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestParseUintParam(t *testing.T) {
+	if n, err := ParseUintParam("18446744073709551615"); err != nil || n != 18446744073709551615 {
+		t.Errorf("Expected the max uint64, but got (%d, %v)", n, err)
+	}
+
+	cases := map[string]string{
+		"-1":                   `"-1" must not be negative`,
+		"abc":                  `"abc" is not an integer`,
+		"01":                   `"01" is not an integer`,
+		"18446744073709551616": `"18446744073709551616" is out of range`,
+	}
+
+	for in, msg := range cases {
+		_, err := ParseUintParam(in)
+
+		var he *HTTPError
+		if !errors.As(err, &he) || he.Status != http.StatusBadRequest || he.Message != msg {
+			t.Errorf("Expected a 400 %s for %q, but got %v", msg, in, err)
+		}
+	}
+}
+
+func TestParsePositiveIntParam(t *testing.T) {
+	if n, err := ParsePositiveIntParam("1"); err != nil || n != 1 {
+		t.Errorf("Expected (1, <nil>), but got (%d, %v)", n, err)
+	}
+
+	for _, s := range []string{"0", "-1", "x"} {
+		if _, err := ParsePositiveIntParam(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func ExampleParsePositiveIntParam() {
+	_, err := ParsePositiveIntParam("0")
+	fmt.Println(err)
+
+	// Output: "0" must be positive
+}
+
+func TestParseIntRanges(t *testing.T) {
+	cases := map[string]string{
+		"":               "[]",
+		"8":              "[8]",
+		"1-5,8,11-13":    "[1 2 3 4 5 8 11 12 13]",
+		"11-13, 1-5 , 8": "[1 2 3 4 5 8 11 12 13]",
+		"1-5,3-7,5":      "[1 2 3 4 5 6 7]",
+		"3,3,3-3":        "[3]",
+		"0-2":            "[0 1 2]",
+	}
+
+	for in, expected := range cases {
+		if out, err := ParseIntRanges(in, 100); err != nil || fmt.Sprint(out) != expected {
+			t.Errorf("Expected %s for %q, but got %v (%v)", expected, in, out, err)
+		}
+	}
+
+	for _, s := range []string{"5-1", "a", "1-b", "1,,2", "1-", "-3", "1-2-3",
+		"1.5", "+1", "99999999999999999999", "0-100", "1-50,60-110"} {
+		if _, err := ParseIntRanges(s, 100); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+
+	// End mustn't overflow
+	s := fmt.Sprintf("%d-%d", math.MaxInt-1, math.MaxInt)
+	expected := fmt.Sprint([]int{math.MaxInt - 1, math.MaxInt})
+	if out, err := ParseIntRanges(s, 2); err != nil || fmt.Sprint(out) != expected {
+		t.Errorf("Expected %s, but got %v (%v)", expected, out, err)
+	}
+
+	if _, err := ParseIntRanges("0-999999999999", 1000); err == nil {
+		t.Error("Expected the limit to be enforced")
+	}
+}
+
+func TestParseIntRangeSpec(t *testing.T) {
+	ranges, err := ParseIntRangeSpec("100-1000000000,1-5,6,8-9,1-2")
+	expected := "[{1 6} {8 9} {100 1000000000}]"
+	if err != nil || fmt.Sprint(ranges) != expected {
+		t.Errorf("Expected %s, but got %v (%v)", expected, ranges, err)
+	}
+
+	ranges, err = ParseIntRangeSpec(fmt.Sprintf("0-%d,5", math.MaxInt))
+	expected = fmt.Sprint([]IntRange{{0, math.MaxInt}})
+	if err != nil || fmt.Sprint(ranges) != expected {
+		t.Errorf("Expected %s, but got %v (%v)", expected, ranges, err)
+	}
+}
+
+func ExampleParseIntRanges() {
+	pages, _ := ParseIntRanges("1-3,7,5-6", 100)
+	fmt.Println(pages)
+
+	// Output: [1 2 3 5 6 7]
+}
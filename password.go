@@ -0,0 +1,112 @@
+package abutil
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	passwordLower   = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigits  = "0123456789"
+	passwordSymbols = "!#$%&*+-=?@^_~"
+
+	// passwordAmbiguous are characters easily confused with each other
+	passwordAmbiguous = "0O1lI"
+)
+
+// PasswordOption configures GeneratePassword
+type PasswordOption func(*passwordConfig)
+
+type passwordConfig struct {
+	noLower, noUpper, noDigits, noSymbols, noAmbiguous bool
+}
+
+// ExcludeLowercase makes GeneratePassword not use lowercase letters
+func ExcludeLowercase() PasswordOption {
+	return func(c *passwordConfig) { c.noLower = true }
+}
+
+// ExcludeUppercase makes GeneratePassword not use uppercase letters
+func ExcludeUppercase() PasswordOption {
+	return func(c *passwordConfig) { c.noUpper = true }
+}
+
+// ExcludeDigits makes GeneratePassword not use digits
+func ExcludeDigits() PasswordOption {
+	return func(c *passwordConfig) { c.noDigits = true }
+}
+
+// ExcludeSymbols makes GeneratePassword not use symbols
+func ExcludeSymbols() PasswordOption {
+	return func(c *passwordConfig) { c.noSymbols = true }
+}
+
+// ExcludeAmbiguous makes GeneratePassword not use characters that are easily
+// confused, like 0 and O or 1 and l
+func ExcludeAmbiguous() PasswordOption {
+	return func(c *passwordConfig) { c.noAmbiguous = true }
+}
+
+// GeneratePassword returns a random password of the given length using
+// crypto/rand. By default it contains at least one lowercase and uppercase
+// letter, digit and symbol each, see the Exclude options to change that.
+func GeneratePassword(length int, opts ...PasswordOption) (string, error) {
+	var c passwordConfig
+	for _, o := range opts {
+		o(&c)
+	}
+
+	var classes []string
+	for _, class := range []struct {
+		chars    string
+		excluded bool
+	}{
+		{passwordLower, c.noLower},
+		{passwordUpper, c.noUpper},
+		{passwordDigits, c.noDigits},
+		{passwordSymbols, c.noSymbols},
+	} {
+		if class.excluded {
+			continue
+		}
+
+		chars := class.chars
+		if c.noAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(passwordAmbiguous, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		classes = append(classes, chars)
+	}
+
+	if len(classes) == 0 {
+		return "", errors.New("abutil: all character classes are excluded")
+	}
+
+	if length < len(classes) {
+		return "", errors.New("abutil: password length is too short to " +
+			"contain all character classes")
+	}
+
+	rnd := cryptoRand()
+	all := strings.Join(classes, "")
+	p := make([]byte, length)
+
+	// One of each class, the rest from all of them
+	for i := range p {
+		chars := all
+		if i < len(classes) {
+			chars = classes[i]
+		}
+		p[i] = chars[rnd.Intn(len(chars))]
+	}
+
+	// Don't keep the required characters at fixed positions
+	ShuffleInPlaceRand(p, rnd)
+
+	return string(p), nil
+}
@@ -0,0 +1,74 @@
+package abutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		p, err := GeneratePassword(12)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(p) != 12 {
+			t.Errorf("Expected 12 characters, but got %q", p)
+		}
+
+		for _, class := range []string{passwordLower, passwordUpper,
+			passwordDigits, passwordSymbols} {
+			if !strings.ContainsAny(p, class) {
+				t.Errorf("Expected %q to contain one of %q", p, class)
+			}
+		}
+	}
+
+	// The required characters aren't always in the same order
+	p, _ := GeneratePassword(4)
+	shuffled := false
+	for i := 0; i < 100 && !shuffled; i++ {
+		p, _ = GeneratePassword(4)
+		shuffled = !strings.ContainsAny(p[:1], passwordLower)
+	}
+
+	if !shuffled {
+		t.Errorf("Expected the classes to be shuffled, but got %q", p)
+	}
+}
+
+func TestGeneratePasswordOptions(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		p, err := GeneratePassword(32, ExcludeAmbiguous())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.ContainsAny(p, passwordAmbiguous) {
+			t.Errorf("Expected no ambiguous characters, but got %q", p)
+		}
+
+		p, err = GeneratePassword(32, ExcludeSymbols(), ExcludeUppercase())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.ContainsAny(p, passwordSymbols+passwordUpper) {
+			t.Errorf("Expected no symbols and uppercase letters, but got %q", p)
+		}
+	}
+
+	if p, err := GeneratePassword(4, ExcludeLowercase(), ExcludeUppercase(),
+		ExcludeSymbols()); err != nil || strings.Trim(p, passwordDigits) != "" {
+		t.Errorf("Expected only digits, but got (%q, %v)", p, err)
+	}
+
+	if _, err := GeneratePassword(3); err == nil {
+		t.Error("Expected an error for a length shorter than the classes")
+	}
+
+	if _, err := GeneratePassword(8, ExcludeLowercase(), ExcludeUppercase(),
+		ExcludeDigits(), ExcludeSymbols()); err == nil {
+		t.Error("Expected an error when excluding all classes")
+	}
+}
@@ -0,0 +1,149 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// pathParamsKey is the context key of the params stored by WithPathParams
+var pathParamsKey = NewContextKey[map[string]string]("path params")
+
+// WithPathParams returns a shallow copy of r carrying the path params, for
+// routers that don't support http.Request.PathValue, or tests
+func WithPathParams(r *http.Request, params map[string]string) *http.Request {
+	return r.WithContext(pathParamsKey.WithValue(r.Context(), params))
+}
+
+// PathParamOption configures PathParam and its typed variants
+type PathParamOption func(*pathParamConfig)
+
+type pathParamConfig struct {
+	source func(r *http.Request, name string) string
+}
+
+// PathParamSource sets the function returning the path param of the
+// request, to support routers without http.Request.PathValue
+func PathParamSource(fn func(r *http.Request, name string) string) PathParamOption {
+	return func(c *pathParamConfig) {
+		c.source = fn
+	}
+}
+
+// PathParam returns the path param name of the request or "" if there is
+// none. By default, it returns the params stored by WithPathParams, falling
+// back to http.Request.PathValue (which the patterns of http.ServeMux
+// provide), see PathParamSource for other routers.
+func PathParam(r *http.Request, name string, opts ...PathParamOption) string {
+	return pathParams(opts).Param(r, name)
+}
+
+// PathParamInt returns the path param name parsed with
+// ParsePositiveIntParam, since integer path params are mostly IDs. Like its
+// errors, the error for a missing param is an *HTTPError with status 400.
+func PathParamInt(r *http.Request, name string, opts ...PathParamOption) (int64, error) {
+	return pathParams(opts).Int(r, name)
+}
+
+// PathParamUUID returns the path param name in lowercase, if it's a UUID in
+// the canonical form, like "f81d4fae-7dec-11d0-a765-00a0c91e6bf6". The
+// errors are *HTTPError with status 400.
+func PathParamUUID(r *http.Request, name string, opts ...PathParamOption) (string, error) {
+	return pathParams(opts).UUID(r, name)
+}
+
+func pathParams(opts []PathParamOption) *PathParams {
+	var c pathParamConfig
+	for _, o := range opts {
+		o(&c)
+	}
+
+	return NewPathParams(c.source)
+}
+
+// PathParams reads path params from a source configured once, e.g. for a
+// router without http.Request.PathValue:
+//
+//	var params = abutil.NewPathParams(chi.URLParam)
+//
+//	id, err := params.Int(r, "id")
+type PathParams struct {
+	source func(r *http.Request, name string) string
+}
+
+// NewPathParams creates PathParams reading from source. A nil source reads
+// the params like PathParam does by default.
+func NewPathParams(source func(r *http.Request, name string) string) *PathParams {
+	if source == nil {
+		source = contextPathParam
+	}
+
+	return &PathParams{source: source}
+}
+
+// Param is like PathParam
+func (p *PathParams) Param(r *http.Request, name string) string {
+	return p.source(r, name)
+}
+
+// Int is like PathParamInt
+func (p *PathParams) Int(r *http.Request, name string) (int64, error) {
+	s := p.Param(r, name)
+	if s == "" {
+		return 0, missingPathParam(name)
+	}
+
+	return ParsePositiveIntParam(s)
+}
+
+// UUID is like PathParamUUID
+func (p *PathParams) UUID(r *http.Request, name string) (string, error) {
+	s := p.Param(r, name)
+	if s == "" {
+		return "", missingPathParam(name)
+	}
+
+	if !isUUID(s) {
+		return "", paramError(s, "is not a UUID")
+	}
+
+	return strings.ToLower(s), nil
+}
+
+// contextPathParam is the default source of PathParam
+func contextPathParam(r *http.Request, name string) string {
+	if params, ok := pathParamsKey.Value(r.Context()); ok {
+		if v, ok := params[name]; ok {
+			return v
+		}
+	}
+
+	return r.PathValue(name)
+}
+
+// isUUID reports if s is a UUID of the form 8-4-4-4-12 hex digits
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func missingPathParam(name string) error {
+	return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("missing parameter %s", name))
+}
@@ -0,0 +1,130 @@
+package abutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func pathParamRequest(params map[string]string) *http.Request {
+	return WithPathParams(httptest.NewRequest("GET", "/", nil), params)
+}
+
+func TestPathParam(t *testing.T) {
+	r := pathParamRequest(map[string]string{"id": "42"})
+	if p := PathParam(r, "id"); p != "42" {
+		t.Errorf("Expected 42, but got %q", p)
+	}
+
+	if p := PathParam(r, "name"); p != "" {
+		t.Errorf("Expected an empty string for a missing param, but got %q", p)
+	}
+
+	// http.ServeMux patterns
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/{name}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(PathParam(r, "name")))
+	})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/users/arne", nil))
+	if w.Body.String() != "arne" {
+		t.Errorf("Expected arne, but got %q", w.Body)
+	}
+}
+
+func TestPathParamSource(t *testing.T) {
+	header := PathParamSource(func(r *http.Request, name string) string {
+		return r.Header.Get("X-Param-" + name)
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Param-Id", "7")
+	if id, err := PathParamInt(r, "id", header); err != nil || id != 7 {
+		t.Errorf("Expected (7, <nil>), but got (%d, %v)", id, err)
+	}
+
+	if p := PathParam(r, "id"); p != "" {
+		t.Errorf("Expected the default source without the option, but got %q", p)
+	}
+}
+
+func TestNewPathParams(t *testing.T) {
+	params := NewPathParams(func(r *http.Request, name string) string {
+		return r.Header.Get("X-Param-" + name)
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Param-Id", "7")
+	r.Header.Set("X-Param-Uuid", "F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6")
+
+	if p := params.Param(r, "id"); p != "7" {
+		t.Errorf("Expected 7, but got %q", p)
+	}
+
+	if id, err := params.Int(r, "id"); err != nil || id != 7 {
+		t.Errorf("Expected (7, <nil>), but got (%d, %v)", id, err)
+	}
+
+	if id, err := params.UUID(r, "uuid"); err != nil ||
+		id != "f81d4fae-7dec-11d0-a765-00a0c91e6bf6" {
+		t.Errorf("Expected the lowercase UUID, but got (%s, %v)", id, err)
+	}
+
+	if _, err := params.Int(r, "missing"); err == nil {
+		t.Error("Expected an error for a missing param")
+	}
+
+	// A nil source uses the default one
+	r = pathParamRequest(map[string]string{"id": "42"})
+	if id, err := NewPathParams(nil).Int(r, "id"); err != nil || id != 42 {
+		t.Errorf("Expected (42, <nil>), but got (%d, %v)", id, err)
+	}
+}
+
+func TestPathParamInt(t *testing.T) {
+	r := pathParamRequest(map[string]string{"id": "42", "bad": "4x", "zero": "0"})
+	if id, err := PathParamInt(r, "id"); err != nil || id != 42 {
+		t.Errorf("Expected (42, <nil>), but got (%d, %v)", id, err)
+	}
+
+	for _, name := range []string{"bad", "zero", "missing"} {
+		id, err := PathParamInt(r, name)
+
+		var he *HTTPError
+		if !errors.As(err, &he) || he.Status != http.StatusBadRequest || id != 0 {
+			t.Errorf("Expected (0, 400 error) for %s, but got (%d, %v)", name, id, err)
+		}
+	}
+}
+
+func TestPathParamUUID(t *testing.T) {
+	r := pathParamRequest(map[string]string{
+		"id":    "F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6",
+		"short": "f81d4fae-7dec-11d0-a765-00a0c91e6bf",
+		"dash":  "f81d4fae7-dec-11d0-a765-00a0c91e6bf6",
+		"hex":   "g81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+	})
+
+	if id, err := PathParamUUID(r, "id"); err != nil ||
+		id != "f81d4fae-7dec-11d0-a765-00a0c91e6bf6" {
+		t.Errorf("Expected the lowercase UUID, but got (%s, %v)", id, err)
+	}
+
+	for _, name := range []string{"short", "dash", "hex", "missing"} {
+		if id, err := PathParamUUID(r, name); err == nil || id != "" {
+			t.Errorf("Expected an error for %s, but got %s", name, id)
+		}
+	}
+}
+
+func ExamplePathParamInt() {
+	r := WithPathParams(httptest.NewRequest("GET", "/users/42", nil),
+		map[string]string{"id": "42"})
+
+	id, err := PathParamInt(r, "id")
+	fmt.Println(id, err)
+
+	// Output: 42 <nil>
+}
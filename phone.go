@@ -0,0 +1,86 @@
+package abutil
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPhone is returned by NormalizePhone for input that can't be a
+// phone number
+var ErrInvalidPhone = errors.New("abutil: invalid phone number")
+
+// CountryCallingCodes maps ISO 3166 region codes to country calling codes,
+// used by NormalizePhone for national numbers. Missing regions can be added
+// at startup, before the first number is normalized.
+var CountryCallingCodes = map[string]string{
+	"US": "1", "CA": "1", "GB": "44", "IE": "353", "DE": "49", "AT": "43",
+	"CH": "41", "FR": "33", "BE": "32", "NL": "31", "LU": "352", "IT": "39",
+	"ES": "34", "PT": "351", "DK": "45", "SE": "46", "NO": "47", "FI": "358",
+	"PL": "48", "CZ": "420", "GR": "30", "RU": "7", "TR": "90", "IL": "972",
+	"ZA": "27", "IN": "91", "CN": "86", "JP": "81", "KR": "82", "SG": "65",
+	"AU": "61", "NZ": "64", "BR": "55", "MX": "52", "AR": "54",
+}
+
+// keepTrunkPrefix are the regions whose national numbers keep their leading
+// 0 in the international format
+var keepTrunkPrefix = map[string]bool{"IT": true}
+
+// NormalizePhone returns the E.164 form (e.g. "+4930123456") of a phone
+// number. Spaces, dashes, dots, slashes, parentheses and a "(0)" after the
+// country code are removed. Numbers starting with + or 00 are international,
+// others are national numbers of defaultRegion (see CountryCallingCodes):
+// their leading 0 (or 1 for the North American regions) is removed and the
+// country code prepended. This is no full validation, but ErrInvalidPhone is
+// returned for clearly invalid input, like letters or too few or many digits.
+func NormalizePhone(raw, defaultRegion string) (string, error) {
+	// "+49 (0)30 ..." marks the trunk prefix, which isn't dialed from abroad
+	s := strings.Replace(strings.TrimSpace(raw), "(0)", "", 1)
+
+	international := strings.HasPrefix(s, "+")
+	if international {
+		s = s[1:]
+	}
+
+	var digits strings.Builder
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case strings.ContainsRune(" -./() ", c):
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	n := digits.String()
+	if !international && strings.HasPrefix(n, "00") {
+		international = true
+		n = n[2:]
+	}
+
+	if !international {
+		region := strings.ToUpper(defaultRegion)
+		cc, ok := CountryCallingCodes[region]
+		if !ok {
+			return "", errors.New("abutil: unknown region " + defaultRegion)
+		}
+
+		switch {
+		case cc == "1" && len(n) == 11 && n[0] == '1':
+			n = n[1:]
+		case cc == "1" && len(n) != 10:
+			return "", ErrInvalidPhone
+		case cc != "1" && !keepTrunkPrefix[region] && strings.HasPrefix(n, "0"):
+			n = n[1:]
+		}
+
+		n = cc + n
+	}
+
+	// E.164 allows at most 15 digits, very short ones are no numbers
+	if len(n) < 8 || len(n) > 15 || n[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + n, nil
+}
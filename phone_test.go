@@ -0,0 +1,59 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	cases := []struct {
+		raw, region, expected string
+	}{
+		{"+49 30 1234567", "US", "+49301234567"},
+		{"0049 (0)30-1234567", "US", "+49301234567"},
+		{"030 / 123 45 67", "DE", "+49301234567"},
+		{"(555) 123-4567", "US", "+15551234567"},
+		{"1-555-123-4567", "us", "+15551234567"},
+		{"555.123.4567", "CA", "+15551234567"},
+		{"020 7946 0958", "GB", "+442079460958"},
+		{"06 12345678", "IT", "+390612345678"},
+		{" +1 555 123 4567 ", "", "+15551234567"},
+	}
+
+	for _, c := range cases {
+		out, err := NormalizePhone(c.raw, c.region)
+		if err != nil || out != c.expected {
+			t.Errorf("Expected (%s, <nil>) for %q, but got (%s, %v)", c.expected,
+				c.raw, out, err)
+		}
+	}
+
+	invalid := []struct {
+		raw, region string
+	}{
+		{"", "DE"},
+		{"call me", "DE"},
+		{"+49 30 123x", "DE"},
+		{"123", "DE"},
+		{"+1234567890123456", "DE"},
+		{"555-1234", "US"},
+		{"+0 123 456789", "DE"},
+	}
+
+	for _, c := range invalid {
+		if out, err := NormalizePhone(c.raw, c.region); err != ErrInvalidPhone {
+			t.Errorf("Expected ErrInvalidPhone for %q, but got (%s, %v)", c.raw, out, err)
+		}
+	}
+
+	if _, err := NormalizePhone("030 1234567", "XX"); err == nil {
+		t.Error("Expected an error for an unknown region")
+	}
+}
+
+func ExampleNormalizePhone() {
+	p, _ := NormalizePhone("(555) 123-4567", "US")
+	fmt.Println(p)
+
+	// Output: +15551234567
+}
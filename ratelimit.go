@@ -0,0 +1,347 @@
+package abutil
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitTTL is how long an idle key's bucket is kept. Buckets idle that
+// long are full again anyway, so evicting them doesn't change the limits.
+const rateLimitTTL = 10 * time.Minute
+
+// RateLimitByIP is a middleware allowing rps requests per second with bursts
+// of up to burst requests per client IP (see RemoteIP). Requests over the
+// limit get a 429 Too Many Requests with a Retry-After header.
+func RateLimitByIP(rps float64, burst int) func(http.Handler) http.Handler {
+	return RateLimitByKey(RemoteIP, rps, burst)
+}
+
+// RateLimitByKey is like RateLimitByIP, but every key returned by keyFn has
+// its own budget, e.g. to limit per user and endpoint:
+//
+//	RateLimitByKey(func(r *http.Request) string {
+//		return userID(r) + " " + r.URL.Path
+//	}, 5, 10)
+func RateLimitByKey(keyFn func(*http.Request) string, rps float64, burst int) func(http.Handler) http.Handler {
+	l := newKeyLimiter(rps, burst, rateLimitTTL)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := l.allow(keyFn(r), time.Now()); !ok {
+				tooManyRequests(w, wait)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tooManyRequests responds with 429 Too Many Requests and a Retry-After of
+// wait, rounded up to seconds
+func tooManyRequests(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, http.StatusText(http.StatusTooManyRequests),
+		http.StatusTooManyRequests)
+}
+
+// keyLimiter holds a token bucket per key and evicts buckets unused for ttl
+type keyLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	ttl       time.Duration
+	buckets   map[string]*keyBucket
+	lastSweep time.Time
+}
+
+type keyBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newKeyLimiter(rps float64, burst int, ttl time.Duration) *keyLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &keyLimiter{
+		rate:    rps,
+		burst:   float64(burst),
+		ttl:     ttl,
+		buckets: map[string]*keyBucket{},
+	}
+}
+
+// allow takes a token of key's bucket. If there is none, it returns false
+// and the time until the next token is available.
+func (l *keyLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &keyBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		if l.rate <= 0 {
+			return false, l.ttl
+		}
+
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// sweep removes buckets unused for ttl, at most once per ttl
+func (l *keyLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.ttl {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.ttl {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// slidingWindowBuckets is the number of sub-windows a SlidingWindowLimiter
+// counts in, so the window slides in steps of a tenth
+const slidingWindowBuckets = 10
+
+// SlidingWindowLimiter allows at most limit requests per key in the trailing
+// window. Unlike the token bucket of RateLimitByKey, it doesn't allow bursts
+// after idle periods, so it suits requirements like "100 requests per
+// minute". Requests are counted in sub-windows of a tenth of the window. It's
+// safe for concurrent use.
+type SlidingWindowLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	bucket    time.Duration
+	counters  map[string]*slidingCounter
+	lastSweep time.Time
+}
+
+// slidingCounter counts the requests of a key per sub-window
+type slidingCounter struct {
+	counts [slidingWindowBuckets]int
+
+	// head is the number of the latest sub-window since the Unix epoch
+	head int64
+}
+
+// NewSlidingWindowLimiter creates a new SlidingWindowLimiter allowing limit
+// requests per window
+func NewSlidingWindowLimiter(limit int, window time.Duration) *SlidingWindowLimiter {
+	bucket := window / slidingWindowBuckets
+	if bucket <= 0 {
+		bucket = 1
+	}
+
+	return &SlidingWindowLimiter{
+		limit:    limit,
+		window:   window,
+		bucket:   bucket,
+		counters: map[string]*slidingCounter{},
+	}
+}
+
+// Allow counts a request of key, if it's within the limit. Otherwise it
+// returns false and the time until a request would be allowed again.
+func (l *SlidingWindowLimiter) Allow(key string) (bool, time.Duration) {
+	return l.allow(key, time.Now())
+}
+
+// Count returns the number of requests of key in the trailing window
+func (l *SlidingWindowLimiter) Count(key string) int {
+	return l.count(key, time.Now())
+}
+
+// Middleware returns a middleware limiting the requests per key returned by
+// keyFn (e.g. RemoteIP). Requests over the limit get a 429 Too Many Requests
+// with a Retry-After header.
+func (l *SlidingWindowLimiter) Middleware(keyFn func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := l.Allow(keyFn(r)); !ok {
+				tooManyRequests(w, wait)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (l *SlidingWindowLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	c, ok := l.counters[key]
+	if !ok {
+		c = &slidingCounter{}
+		l.counters[key] = c
+	}
+
+	cur := l.advance(c, now)
+	n := c.sum()
+	if n < l.limit {
+		c.counts[cur%slidingWindowBuckets]++
+		return true, 0
+	}
+
+	// Wait for the oldest sub-windows to leave the window until enough
+	// requests are freed
+	b := cur - slidingWindowBuckets + 1
+	for freed := 0; b <= cur; b++ {
+		if freed += c.counts[b%slidingWindowBuckets]; freed > n-l.limit {
+			break
+		}
+	}
+
+	leaves := time.Unix(0, (b+slidingWindowBuckets)*int64(l.bucket))
+	return false, leaves.Sub(now)
+}
+
+func (l *SlidingWindowLimiter) count(key string, now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c, ok := l.counters[key]
+	if !ok {
+		return 0
+	}
+
+	l.advance(c, now)
+	return c.sum()
+}
+
+// advance moves the head of c to the sub-window of now, clearing the ones
+// which left the window, and returns its number
+func (l *SlidingWindowLimiter) advance(c *slidingCounter, now time.Time) int64 {
+	cur := now.UnixNano() / int64(l.bucket)
+	if cur-c.head >= slidingWindowBuckets {
+		c.counts = [slidingWindowBuckets]int{}
+	} else {
+		for b := c.head + 1; b <= cur; b++ {
+			c.counts[b%slidingWindowBuckets] = 0
+		}
+	}
+
+	if cur > c.head {
+		c.head = cur
+	}
+
+	return c.head
+}
+
+func (c *slidingCounter) sum() int {
+	n := 0
+	for _, count := range c.counts {
+		n += count
+	}
+
+	return n
+}
+
+// sweep removes the counters without requests in the window, at most once
+// per window
+func (l *SlidingWindowLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+
+	cur := now.UnixNano() / int64(l.bucket)
+	for key, c := range l.counters {
+		if cur-c.head >= slidingWindowBuckets {
+			delete(l.counters, key)
+		}
+	}
+}
+
+// LeakyBucket spaces calls evenly at a fixed rate. Unlike a token bucket,
+// unused capacity doesn't accumulate, so there are no bursts, which suits
+// calling third-party APIs with strict rate limits. It's safe for concurrent
+// use.
+type LeakyBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+
+	// never is set if the rate allows no calls at all
+	never bool
+
+	// next is the time of the next free slot
+	next time.Time
+}
+
+// NewLeakyBucket creates a new LeakyBucket allowing rate calls per second.
+// A rate <= 0 (or so low that the interval overflows a time.Duration)
+// allows no calls.
+func NewLeakyBucket(rate float64) *LeakyBucket {
+	interval := float64(time.Second) / rate
+	if !(rate > 0) || interval > math.MaxInt64 {
+		return &LeakyBucket{never: true}
+	}
+
+	return &LeakyBucket{interval: time.Duration(interval)}
+}
+
+// Take waits until the next slot, which is at least 1/rate after the
+// previous one, and returns nil. If ctx is done first, it returns ctx.Err().
+func (l *LeakyBucket) Take(ctx context.Context) error {
+	if l.never {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	slot := l.next
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := slot.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// Free the slot, unless later calls already took the following ones
+		l.mu.Lock()
+		if l.next.Equal(slot.Add(l.interval)) {
+			l.next = slot
+		}
+		l.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,200 @@
+package abutil
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func rateLimitRequest(h http.Handler, remoteAddr, path string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", path, nil)
+	r.RemoteAddr = remoteAddr
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	h := RateLimitByIP(1, 2)(http.NotFoundHandler())
+
+	for i := 0; i < 2; i++ {
+		if w := rateLimitRequest(h, "1.2.3.4:1234", "/"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected request %d to pass, but got %d", i, w.Code)
+		}
+	}
+
+	w := rateLimitRequest(h, "1.2.3.4:1234", "/")
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 after the burst, but got %d", w.Code)
+	}
+
+	if ra := w.Header().Get("Retry-After"); ra != "1" {
+		t.Errorf("Expected Retry-After 1, but got %q", ra)
+	}
+
+	if w := rateLimitRequest(h, "5.6.7.8:1234", "/"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected another IP to pass, but got %d", w.Code)
+	}
+}
+
+func TestRateLimitByKey(t *testing.T) {
+	h := RateLimitByKey(func(r *http.Request) string {
+		return RemoteIP(r) + " " + r.URL.Path
+	}, 1, 1)(http.NotFoundHandler())
+
+	// Every endpoint has its own budget
+	for _, path := range []string{"/expensive", "/cheap"} {
+		if w := rateLimitRequest(h, "1.2.3.4:1234", path); w.Code != http.StatusNotFound {
+			t.Errorf("Expected the first request to %s to pass, but got %d", path, w.Code)
+		}
+
+		if w := rateLimitRequest(h, "1.2.3.4:1234", path); w.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected the second request to %s to be limited, but got %d", path, w.Code)
+		}
+	}
+}
+
+func TestKeyLimiter(t *testing.T) {
+	l := newKeyLimiter(2, 1, time.Minute)
+	now := time.Now()
+
+	if ok, _ := l.allow("foo", now); !ok {
+		t.Error("Expected the first request to pass")
+	}
+
+	if ok, wait := l.allow("foo", now); ok || wait != 500*time.Millisecond {
+		t.Errorf("Expected (false, 500ms), but got (%t, %s)", ok, wait)
+	}
+
+	// Tokens are refilled over time
+	if ok, _ := l.allow("foo", now.Add(500*time.Millisecond)); !ok {
+		t.Error("Expected a request to pass after refilling")
+	}
+
+	// Idle buckets are evicted
+	l.allow("bar", now.Add(2*time.Minute))
+	if len(l.buckets) != 1 {
+		t.Errorf("Expected 1 bucket after eviction, but got %d", len(l.buckets))
+	}
+}
+
+func TestSlidingWindowLimiter(t *testing.T) {
+	l := NewSlidingWindowLimiter(3, 10*time.Second)
+	base := time.Unix(1000, 0)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("foo", base.Add(time.Duration(i)*time.Second)); !ok {
+			t.Errorf("Expected request %d to pass", i)
+		}
+	}
+
+	if n := l.count("foo", base.Add(2*time.Second)); n != 3 {
+		t.Errorf("Expected a count of 3, but got %d", n)
+	}
+
+	// The first request leaves the window after 10s
+	if ok, wait := l.allow("foo", base.Add(5*time.Second)); ok || wait != 5*time.Second {
+		t.Errorf("Expected (false, 5s), but got (%t, %s)", ok, wait)
+	}
+
+	if ok, _ := l.allow("bar", base.Add(5*time.Second)); !ok {
+		t.Error("Expected another key to pass")
+	}
+
+	if ok, _ := l.allow("foo", base.Add(10*time.Second)); !ok {
+		t.Error("Expected a request to pass after the first one left the window")
+	}
+
+	if ok, wait := l.allow("foo", base.Add(10500*time.Millisecond)); ok || wait != 500*time.Millisecond {
+		t.Errorf("Expected (false, 500ms), but got (%t, %s)", ok, wait)
+	}
+
+	if n := l.count("foo", base.Add(30*time.Second)); n != 0 {
+		t.Errorf("Expected a count of 0 after the window, but got %d", n)
+	}
+
+	// Counters without requests in the window are evicted
+	l.allow("baz", base.Add(60*time.Second))
+	if len(l.counters) != 1 {
+		t.Errorf("Expected 1 counter after eviction, but got %d", len(l.counters))
+	}
+}
+
+func TestSlidingWindowLimiterMiddleware(t *testing.T) {
+	l := NewSlidingWindowLimiter(2, time.Minute)
+	h := l.Middleware(RemoteIP)(http.NotFoundHandler())
+
+	for i := 0; i < 2; i++ {
+		if w := rateLimitRequest(h, "1.2.3.4:1234", "/"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected request %d to pass, but got %d", i, w.Code)
+		}
+	}
+
+	w := rateLimitRequest(h, "1.2.3.4:1234", "/")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 429 with Retry-After, but got %d %v", w.Code, w.Header())
+	}
+
+	if n := l.Count("1.2.3.4"); n != 2 {
+		t.Errorf("Expected a count of 2, but got %d", n)
+	}
+}
+
+func TestLeakyBucket(t *testing.T) {
+	l := NewLeakyBucket(50)
+
+	// Timers don't fire early, so 5 calls take at least 4 intervals
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Take(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 80*time.Millisecond {
+		t.Errorf("Expected 5 calls to take at least 80ms, but took %s", d)
+	}
+
+	// Idle time doesn't accumulate
+	time.Sleep(60 * time.Millisecond)
+	start = time.Now()
+	l.Take(context.Background())
+	l.Take(context.Background())
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("Expected the second Take after idling to wait, but took %s", d)
+	}
+}
+
+func TestLeakyBucketInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, math.NaN(), 1e-300} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		if err := NewLeakyBucket(rate).Take(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Expected no calls with rate %v, but got %v", rate, err)
+		}
+		cancel()
+	}
+}
+
+func TestLeakyBucketContext(t *testing.T) {
+	l := NewLeakyBucket(1)
+	l.Take(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Take(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, but got %v", err)
+	}
+
+	// The cancelled call freed its slot
+	l.mu.Lock()
+	wait := time.Until(l.next)
+	l.mu.Unlock()
+	if wait > time.Second {
+		t.Errorf("Expected the next slot within a second, but got %s", wait)
+	}
+}
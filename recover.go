@@ -0,0 +1,83 @@
+package abutil
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recoverer is a middleware that recovers from panics in the handler, logs
+// them with the stack trace and responds with a 500. If the handler already
+// started writing the response, it's left as is to avoid garbling it.
+func Recoverer(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := NewStatusWriter(w)
+
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			// Used by net/http to abort a response on purpose
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			log.Printf("abutil: panic serving %s %s: %v\n%s", r.Method,
+				r.URL.Path, p, debug.Stack())
+
+			if !sw.Written() {
+				http.Error(sw, http.StatusText(http.StatusInternalServerError),
+					http.StatusInternalServerError)
+			}
+		}()
+
+		h.ServeHTTP(sw, r)
+	})
+}
+
+// PanicHandler is called by Go and GoCtx with the value and stack trace of a
+// recovered panic
+type PanicHandler func(p interface{}, stack []byte)
+
+// logPanic is the PanicHandler used if none is given
+func logPanic(p interface{}, stack []byte) {
+	log.Printf("abutil: panic in goroutine: %v\n%s", p, stack)
+}
+
+// Go runs fn in a goroutine, recovering from panics, which are passed to
+// onPanic instead of crashing the process. If onPanic is nil, they're logged.
+func Go(fn func(), onPanic PanicHandler) {
+	go func() {
+		defer recoverGoroutine(onPanic)
+		fn()
+	}()
+}
+
+// GoCtx is like Go, but passes ctx to fn, which should return once ctx is
+// done. If ctx is already done, fn isn't run.
+func GoCtx(ctx context.Context, fn func(ctx context.Context), onPanic PanicHandler) {
+	go func() {
+		defer recoverGoroutine(onPanic)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		fn(ctx)
+	}()
+}
+
+func recoverGoroutine(onPanic PanicHandler) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	if onPanic == nil {
+		onPanic = logPanic
+	}
+	onPanic(p, debug.Stack())
+}
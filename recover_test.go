@@ -0,0 +1,158 @@
+package abutil
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// headerCounter counts the WriteHeader calls
+type headerCounter struct {
+	*httptest.ResponseRecorder
+	calls int
+}
+
+func (h *headerCounter) WriteHeader(code int) {
+	h.calls++
+	h.ResponseRecorder.WriteHeader(code)
+}
+
+func (h *headerCounter) Write(b []byte) (int, error) {
+	if h.calls == 0 {
+		h.WriteHeader(http.StatusOK)
+	}
+
+	return h.ResponseRecorder.Write(b)
+}
+
+func TestRecoverer(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	h := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("Something went wrong")
+	}))
+
+	r, _ := http.NewRequest("GET", "http://some.url/foo", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, but got %d",
+			http.StatusInternalServerError, w.Code)
+	}
+
+	if !strings.Contains(buf.String(), "Something went wrong") {
+		t.Errorf("Expected the panic to be logged, but got %q", buf.String())
+	}
+
+	// The handler already wrote
+	h = Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Foo"))
+		panic("Something went wrong")
+	}))
+
+	hc := &headerCounter{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(hc, r)
+
+	if hc.calls != 1 || hc.Code != http.StatusOK {
+		t.Errorf("Expected 1 header write with %d, but got %d with %d",
+			http.StatusOK, hc.calls, hc.Code)
+	}
+
+	if hc.Body.String() != "Foo" {
+		t.Errorf("Expected body Foo, but got %q", hc.Body.String())
+	}
+
+	// http.ErrAbortHandler is passed through
+	h = Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("Expected panic %v, but got %v", http.ErrAbortHandler, p)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+// capturePanics returns a PanicHandler sending recovered panics to the
+// returned channel
+func capturePanics() (PanicHandler, <-chan interface{}) {
+	panics := make(chan interface{}, 1)
+	return func(p interface{}, stack []byte) {
+		if !bytes.Contains(stack, []byte("recover_test.go")) {
+			p = "missing stack trace"
+		}
+
+		panics <- p
+	}, panics
+}
+
+func TestGo(t *testing.T) {
+	onPanic, panics := capturePanics()
+
+	Go(func() {
+		panic("foo")
+	}, onPanic)
+
+	if p := <-panics; p != "foo" {
+		t.Errorf("Expected the panic foo, but got %v", p)
+	}
+
+	// Without a handler, panics are logged
+	logged := make(chan string, 1)
+	log.SetOutput(chanWriter(logged))
+	defer log.SetOutput(os.Stderr)
+
+	Go(func() {
+		panic("logged")
+	}, nil)
+
+	if l := <-logged; !strings.Contains(l, "logged") {
+		t.Errorf("Expected the panic to be logged, but got %q", l)
+	}
+}
+
+// chanWriter sends everything written to it to the channel
+type chanWriter chan string
+
+func (c chanWriter) Write(p []byte) (int, error) {
+	c <- string(p)
+	return len(p), nil
+}
+
+func TestGoCtx(t *testing.T) {
+	onPanic, panics := capturePanics()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	GoCtx(ctx, func(ctx context.Context) {
+		cancel()
+		<-ctx.Done()
+		panic("bar")
+	}, onPanic)
+
+	if p := <-panics; p != "bar" {
+		t.Errorf("Expected the panic bar, but got %v", p)
+	}
+
+	// Cancelled contexts don't run fn
+	ran := make(chan struct{})
+	GoCtx(ctx, func(ctx context.Context) {
+		close(ran)
+	}, onPanic)
+
+	select {
+	case <-ran:
+		t.Error("Expected fn not to run with a cancelled context")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,45 @@
+package abutil
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// acmeChallengePrefix is the path used by ACME (e.g. Let's Encrypt) for
+// http-01 challenges
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+// RedirectHTTPS is a middleware that redirects plain HTTP requests (see
+// RequestScheme) to their HTTPS equivalent with a 301. ACME challenge paths
+// are passed through, so autocert keeps working.
+func RedirectHTTPS(h http.Handler) http.Handler {
+	return RedirectHTTPSCode(http.StatusMovedPermanently)(h)
+}
+
+// RedirectHTTPSCode is like RedirectHTTPS, but redirects with the given
+// status code (e.g. http.StatusPermanentRedirect to keep the method)
+func RedirectHTTPSCode(code int) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if RequestScheme(r) != "http" ||
+				strings.HasPrefix(r.URL.Path, acmeChallengePrefix) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			// The HTTPS port is most likely not the HTTP one
+			host := r.Host
+			if hst, _, err := net.SplitHostPort(host); err == nil {
+				host = hst
+
+				// SplitHostPort strips the brackets of IPv6 addresses
+				if strings.Contains(host, ":") {
+					host = "[" + host + "]"
+				}
+			}
+
+			http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), code)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectHTTPS(t *testing.T) {
+	h := RedirectHTTPS(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("Foobar"))
+		}))
+
+	r, _ := http.NewRequest("GET", "http://some.url:8080/foo?bar=baz", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Errorf("Expected status %d, but got %d", http.StatusMovedPermanently,
+			w.Code)
+	}
+
+	loc := "https://some.url/foo?bar=baz"
+	if w.Header().Get("Location") != loc {
+		t.Errorf("Expected Location %s, but got %s", loc,
+			w.Header().Get("Location"))
+	}
+
+	// IPv6 hosts keep their brackets
+	for host, loc := range map[string]string{
+		"[::1]:8080": "https://[::1]/foo",
+		"[::1]":      "https://[::1]/foo",
+	} {
+		r, _ = http.NewRequest("GET", "http://"+host+"/foo", nil)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Header().Get("Location") != loc {
+			t.Errorf("Expected Location %s, but got %s", loc,
+				w.Header().Get("Location"))
+		}
+	}
+
+	// ACME challenges must not be redirected
+	r, _ = http.NewRequest("GET",
+		"http://some.url/.well-known/acme-challenge/token", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK || w.Body.String() != "Foobar" {
+		t.Errorf("Expected challenge to pass through, but got %d", w.Code)
+	}
+
+	// Already HTTPS behind a proxy
+	r, _ = http.NewRequest("GET", "http://some.url/foo", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, but got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestRedirectHTTPSCode(t *testing.T) {
+	h := RedirectHTTPSCode(http.StatusPermanentRedirect)(http.NotFoundHandler())
+
+	r, _ := http.NewRequest("POST", "http://some.url/foo", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusPermanentRedirect {
+		t.Errorf("Expected status %d, but got %d", http.StatusPermanentRedirect,
+			w.Code)
+	}
+}
+
+func ExampleRedirectHTTPS() {
+	h := RedirectHTTPS(http.NotFoundHandler())
+
+	r, _ := http.NewRequest("GET", "http://some.url/foo", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	fmt.Println(w.Code, w.Header().Get("Location"))
+
+	// Output: 301 https://some.url/foo
+}
@@ -0,0 +1,55 @@
+package abutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// IsRetryable reports if err is a transient failure, which might not occur
+// again if retried: network timeouts, refused, reset or aborted connections,
+// connections closed unexpectedly (io.ErrUnexpectedEOF, io.EOF), temporary
+// DNS failures and *HTTPError with a status RetryableHTTPStatus accepts.
+// Cancelled contexts, exceeded deadlines and other errors aren't retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return RetryableHTTPStatus(he.Status)
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+
+	// Servers closing idle keep-alive connections result in an io.EOF
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// RetryableHTTPStatus reports if a response with the status code is worth
+// retrying: 429 Too Many Requests, 502 Bad Gateway, 503 Service Unavailable
+// and 504 Gateway Timeout
+func RetryableHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package abutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// timeoutError is a net.Error timing out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	dial := func(errno syscall.Errno) error {
+		return &net.OpError{Op: "dial", Net: "tcp",
+			Err: os.NewSyscallError("connect", errno)}
+	}
+
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{nil, false},
+		{timeoutError{}, true},
+		{&url.Error{Op: "Get", URL: "http://some.url", Err: timeoutError{}}, true},
+		{dial(syscall.ECONNREFUSED), true},
+		{fmt.Errorf("reading: %w", dial(syscall.ECONNRESET)), true},
+		{dial(syscall.EACCES), false},
+		{io.ErrUnexpectedEOF, true},
+		{&url.Error{Op: "Get", URL: "http://some.url", Err: io.EOF}, true},
+		{&net.DNSError{Err: "server misbehaving", IsTemporary: true}, true},
+		{&net.DNSError{Err: "no such host", IsNotFound: true}, false},
+		{context.Canceled, false},
+		{&url.Error{Op: "Get", URL: "http://some.url", Err: context.DeadlineExceeded}, false},
+		{NewHTTPError(http.StatusServiceUnavailable, "down"), true},
+		{fmt.Errorf("calling: %w", NewHTTPError(http.StatusNotFound, "gone")), false},
+		{errors.New("invalid input"), false},
+	}
+
+	for _, c := range cases {
+		if out := IsRetryable(c.err); out != c.retryable {
+			t.Errorf("Expected %t for %v, but got %t", c.retryable, c.err, out)
+		}
+	}
+}
+
+func TestRetryableHTTPStatus(t *testing.T) {
+	for code := 100; code < 600; code++ {
+		expected := code == 429 || code == 502 || code == 503 || code == 504
+		if out := RetryableHTTPStatus(code); out != expected {
+			t.Errorf("Expected %t for %d, but got %t", expected, code, out)
+		}
+	}
+}
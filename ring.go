@@ -0,0 +1,154 @@
+package abutil
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// defaultRingReplicas is the number of virtual nodes per node of a Ring if
+// NewRing is passed 0
+const defaultRingReplicas = 160
+
+// Ring is a consistent hash ring, which maps keys to nodes (e.g. cache
+// backends) so that adding or removing a node only remaps about 1/n of the
+// keys. Every node is placed on the ring multiple times (virtual nodes) to
+// balance the keys. It's safe for concurrent use.
+type Ring struct {
+	mu       sync.RWMutex
+	replicas int
+	nodes    map[string]struct{}
+
+	// hashes are the sorted hashes of the virtual nodes
+	hashes []uint64
+	owners map[uint64]string
+}
+
+// NewRing creates a new Ring with the given number of virtual nodes per
+// node (160 for 0) and adds the nodes
+func NewRing(replicas int, nodes ...string) *Ring {
+	if replicas <= 0 {
+		replicas = defaultRingReplicas
+	}
+
+	r := &Ring{
+		replicas: replicas,
+		nodes:    map[string]struct{}{},
+		owners:   map[uint64]string{},
+	}
+	for _, n := range nodes {
+		r.AddNode(n)
+	}
+
+	return r
+}
+
+// AddNode adds the node, if it's not in the ring yet
+func (r *Ring) AddNode(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.nodes[node]; ok {
+		return
+	}
+	r.nodes[node] = struct{}{}
+
+	for i := 0; i < r.replicas; i++ {
+		h := ringHash(node + "#" + strconv.Itoa(i))
+
+		// On the unlikely collision, the smaller node wins, so the result
+		// doesn't depend on the order nodes were added
+		if owner, ok := r.owners[h]; ok {
+			if owner < node {
+				continue
+			}
+		} else {
+			r.hashes = append(r.hashes, h)
+		}
+		r.owners[h] = node
+	}
+
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// RemoveNode removes the node, its keys are distributed to the others
+func (r *Ring) RemoveNode(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.nodes[node]; !ok {
+		return
+	}
+	delete(r.nodes, node)
+
+	hashes := r.hashes[:0]
+	for _, h := range r.hashes {
+		if r.owners[h] == node {
+			delete(r.owners, h)
+			continue
+		}
+		hashes = append(hashes, h)
+	}
+	r.hashes = hashes
+
+	// Restore the virtual nodes of others which collided with the node
+	for n := range r.nodes {
+		for i := 0; i < r.replicas; i++ {
+			if h := ringHash(n + "#" + strconv.Itoa(i)); r.owners[h] == "" {
+				r.owners[h] = n
+				r.hashes = append(r.hashes, h)
+			}
+		}
+	}
+
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// Get returns the node responsible for key, or "" if the ring is empty
+func (r *Ring) Get(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	h := ringHash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+
+	return r.owners[r.hashes[i]]
+}
+
+// Nodes returns the nodes of the ring, sorted
+func (r *Ring) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes := make([]string, 0, len(r.nodes))
+	for n := range r.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	return nodes
+}
+
+// ringHash returns the 64-bit FNV-1a hash of s, mixed with the finalizer of
+// MurmurHash3, since FNV alone distributes similar strings poorly
+func ringHash(s string) uint64 {
+	f := fnv.New64a()
+	f.Write([]byte(s))
+
+	h := f.Sum64()
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h
+}
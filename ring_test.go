@@ -0,0 +1,82 @@
+package abutil
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func ringAssignments(r *Ring, n int) map[string]string {
+	m := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		key := "key" + strconv.Itoa(i)
+		m[key] = r.Get(key)
+	}
+
+	return m
+}
+
+func TestRing(t *testing.T) {
+	if node := NewRing(0).Get("foo"); node != "" {
+		t.Errorf("Expected no node for an empty ring, but got %s", node)
+	}
+
+	r := NewRing(0, "a", "b", "c")
+	before := ringAssignments(r, 10000)
+
+	counts := map[string]int{}
+	for _, node := range before {
+		counts[node]++
+	}
+	for _, node := range []string{"a", "b", "c"} {
+		if counts[node] < 2500 || counts[node] > 4200 {
+			t.Errorf("Expected about a third of the keys for %s, but got %d", node,
+				counts[node])
+		}
+	}
+
+	// Adding a node only moves keys to it
+	r.AddNode("d")
+	moved := 0
+	for key, node := range ringAssignments(r, 10000) {
+		if node != before[key] {
+			moved++
+			if node != "d" {
+				t.Fatalf("Expected %s to move to d, but it moved to %s", key, node)
+			}
+		}
+	}
+	if moved < 1500 || moved > 3500 {
+		t.Errorf("Expected about a quarter of the keys to move, but got %d", moved)
+	}
+
+	// Removing a node restores the previous assignments
+	r.RemoveNode("d")
+	for key, node := range ringAssignments(r, 10000) {
+		if node != before[key] {
+			t.Fatalf("Expected %s to be assigned to %s again, but got %s", key,
+				before[key], node)
+		}
+	}
+
+	// Only the keys of a removed node move
+	r.RemoveNode("b")
+	for key, node := range ringAssignments(r, 10000) {
+		if before[key] != "b" && node != before[key] {
+			t.Fatalf("Expected %s to stay on %s, but it moved to %s", key,
+				before[key], node)
+		}
+	}
+
+	if nodes := r.Nodes(); fmt.Sprint(nodes) != "[a c]" {
+		t.Errorf("Expected [a c], but got %v", nodes)
+	}
+}
+
+func BenchmarkRingGet(b *testing.B) {
+	r := NewRing(0, "a", "b", "c", "d", "e")
+
+	for i := 0; i < b.N; i++ {
+		r.Get("some-cache-key")
+	}
+}
@@ -0,0 +1,222 @@
+package abutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a semantic version (https://semver.org), like 1.2.3-beta.1+build
+type SemVer struct {
+	Major, Minor, Patch uint64
+
+	// Prerelease are the dot-separated identifiers after the -, if any
+	Prerelease []string
+
+	// Build is the metadata after the +, which is ignored for precedence
+	Build string
+}
+
+// ParseSemVer parses a semantic version, optionally prefixed with "v", like
+// "v1.2.3", "1.0.0-rc.1" or "1.0.0+20160301"
+func ParseSemVer(s string) (SemVer, error) {
+	invalid := fmt.Errorf("abutil: invalid semantic version %q", s)
+
+	var v SemVer
+	rest := strings.TrimPrefix(s, "v")
+
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		v.Build = rest[i+1:]
+		rest = rest[:i]
+		if !validSemVerIdentifiers(v.Build, false) {
+			return SemVer{}, invalid
+		}
+	}
+
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		pre := rest[i+1:]
+		rest = rest[:i]
+		if !validSemVerIdentifiers(pre, true) {
+			return SemVer{}, invalid
+		}
+		v.Prerelease = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return SemVer{}, invalid
+	}
+
+	nums := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		if !isParamNumber(p) {
+			return SemVer{}, invalid
+		}
+
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return SemVer{}, invalid
+		}
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+// validSemVerIdentifiers reports if s consists of dot-separated, non-empty
+// identifiers of alphanumerics and hyphens. Numeric prerelease identifiers
+// must not have leading zeros.
+func validSemVerIdentifiers(s string, prerelease bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+
+		numeric := true
+		for _, c := range id {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+
+		if prerelease && numeric && len(id) > 1 && id[0] == '0' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the version without the "v" prefix
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+
+	return s
+}
+
+// CompareSemVer returns -1 if a has a lower precedence than b, 1 if it has a
+// higher one and 0 if they're equal. A prerelease has a lower precedence
+// than its release, build metadata is ignored.
+func CompareSemVer(a, b SemVer) int {
+	for _, p := range [][2]uint64{{a.Major, b.Major}, {a.Minor, b.Minor},
+		{a.Patch, b.Patch}} {
+		if c := compareUint(p[0], p[1]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(a.Prerelease) == 0 && len(b.Prerelease) == 0:
+		return 0
+	case len(a.Prerelease) == 0:
+		return 1
+	case len(b.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.Prerelease) && i < len(b.Prerelease); i++ {
+		if c := comparePrerelease(a.Prerelease[i], b.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareUint(uint64(len(a.Prerelease)), uint64(len(b.Prerelease)))
+}
+
+// comparePrerelease compares numeric identifiers numerically and others
+// lexically, numeric ones have a lower precedence
+func comparePrerelease(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// Satisfies reports if v satisfies the constraint, which consists of
+// space-separated comparisons that must all match, like ">=1.2.0 <2.0.0".
+// The operators are =, !=, >, >=, < and <=, a version without operator must
+// be equal. Alternatives can be separated by ||, like "1.0.0 || >=2.0.0".
+func (v SemVer) Satisfies(constraint string) (bool, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return false, fmt.Errorf("abutil: invalid constraint %q", constraint)
+	}
+
+	satisfied := false
+	for _, alt := range strings.Split(constraint, "||") {
+		comparisons := strings.Fields(alt)
+		if len(comparisons) == 0 {
+			return false, fmt.Errorf("abutil: invalid constraint %q", constraint)
+		}
+
+		ok := true
+		for _, cmp := range comparisons {
+			match, err := v.satisfiesComparison(cmp)
+			if err != nil {
+				return false, err
+			}
+			ok = ok && match
+		}
+
+		satisfied = satisfied || ok
+	}
+
+	return satisfied, nil
+}
+
+// satisfiesComparison checks a single comparison, like ">=1.2.0"
+func (v SemVer) satisfiesComparison(cmp string) (bool, error) {
+	op := cmp[:len(cmp)-len(strings.TrimLeft(cmp, "=!<>"))]
+
+	other, err := ParseSemVer(cmp[len(op):])
+	if err != nil {
+		return false, fmt.Errorf("abutil: invalid constraint %q: %w", cmp, err)
+	}
+
+	c := CompareSemVer(v, other)
+	switch op {
+	case "", "=":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	}
+
+	return false, fmt.Errorf("abutil: invalid operator %q", op)
+}
@@ -0,0 +1,109 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	cases := map[string]SemVer{
+		"1.2.3":               {Major: 1, Minor: 2, Patch: 3},
+		"v0.0.1":              {Patch: 1},
+		"1.0.0-beta.1":        {Major: 1, Prerelease: []string{"beta", "1"}},
+		"1.0.0+20160301":      {Major: 1, Build: "20160301"},
+		"1.0.0-rc-1+build.01": {Major: 1, Prerelease: []string{"rc-1"}, Build: "build.01"},
+	}
+
+	for s, expected := range cases {
+		v, err := ParseSemVer(s)
+		if err != nil || fmt.Sprint(v) != fmt.Sprint(expected) {
+			t.Errorf("Expected %v for %q, but got %v (%v)", expected, s, v, err)
+		}
+	}
+
+	for _, s := range []string{"", "1", "1.2", "1.2.3.4", "01.2.3", "1.2.x",
+		"1.2.3-", "1.2.3-beta..1", "1.2.3-01", "1.2.3+", "1.2.3-bé", " 1.2.3",
+		"-1.2.3"} {
+		if _, err := ParseSemVer(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestSemVerString(t *testing.T) {
+	for _, s := range []string{"1.2.3", "1.0.0-beta.1", "1.0.0-rc.1+build.5"} {
+		v, _ := ParseSemVer(s)
+		if v.String() != s {
+			t.Errorf("Expected %s, but got %s", s, v)
+		}
+	}
+}
+
+func TestCompareSemVer(t *testing.T) {
+	// The example of the spec, in ascending precedence
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta",
+		"1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0",
+		"1.0.1", "1.1.0", "2.0.0", "10.0.0"}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := ParseSemVer(ordered[i])
+			b, _ := ParseSemVer(ordered[j])
+
+			expected := compareUint(uint64(i), uint64(j))
+			if c := CompareSemVer(a, b); c != expected {
+				t.Errorf("Expected %d comparing %s and %s, but got %d", expected,
+					a, b, c)
+			}
+		}
+	}
+
+	a, _ := ParseSemVer("1.0.0+foo")
+	b, _ := ParseSemVer("1.0.0+bar")
+	if CompareSemVer(a, b) != 0 {
+		t.Error("Expected the build metadata to be ignored")
+	}
+}
+
+func TestSemVerSatisfies(t *testing.T) {
+	cases := []struct {
+		version, constraint string
+		expected            bool
+	}{
+		{"1.5.0", ">=1.2.0 <2.0.0", true},
+		{"1.2.0", ">=1.2.0 <2.0.0", true},
+		{"2.0.0", ">=1.2.0 <2.0.0", false},
+		{"2.0.0-rc.1", ">=1.2.0 <2.0.0", true},
+		{"1.0.0", "1.0.0", true},
+		{"1.0.0", "=v1.0.0", true},
+		{"1.0.1", "!=1.0.0", true},
+		{"1.0.0", ">1.0.0", false},
+		{"1.0.0", "<=1.0.0", true},
+		{"1.5.0", "1.0.0 || >=1.4.0", true},
+		{"1.3.0", "1.0.0 || >=1.4.0", false},
+	}
+
+	for _, c := range cases {
+		v, _ := ParseSemVer(c.version)
+		if ok, err := v.Satisfies(c.constraint); err != nil || ok != c.expected {
+			t.Errorf("Expected %t for %s %q, but got %t (%v)", c.expected, c.version,
+				c.constraint, ok, err)
+		}
+	}
+
+	v, _ := ParseSemVer("1.0.0")
+	for _, c := range []string{"", ">= 1.0.0", "~1.0.0", ">=1.0", "1.0.0 ||", "=>1.0.0"} {
+		if _, err := v.Satisfies(c); err == nil {
+			t.Errorf("Expected an error for %q", c)
+		}
+	}
+}
+
+func ExampleSemVer_Satisfies() {
+	v, _ := ParseSemVer("v1.4.2")
+	ok, _ := v.Satisfies(">=1.2.0 <2.0.0")
+
+	fmt.Println(v, ok)
+
+	// Output: 1.4.2 true
+}
@@ -0,0 +1,120 @@
+package abutil
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// shadowTimeout limits how long a shadow request may take
+const shadowTimeout = 30 * time.Second
+
+// ShadowMiddleware sends a copy of each request to the shadow backend at
+// shadowURL (its path is prepended) while the next handler serves the real
+// response. The shadow request runs asynchronously and its errors are only
+// logged, so it never affects the primary response. Requests with a body
+// larger than maxBytes aren't shadowed. If compare isn't nil, it's called
+// with both responses once the shadow responded; their bodies are buffered
+// and closed afterwards.
+func ShadowMiddleware(shadowURL *url.URL, maxBytes int64, compare func(primary, shadow *http.Response)) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := DrainAndRewind(r, maxBytes)
+			if err != nil {
+				log.Printf("abutil: shadow: reading body: %v", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			req, cancel := shadowRequest(r, shadowURL, body)
+
+			if compare == nil {
+				go func() {
+					if resp, err := sendShadow(req, cancel); err == nil {
+						resp.Body.Close()
+					}
+				}()
+
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			shadowc := make(chan *http.Response, 1)
+			go func() {
+				resp, _ := sendShadow(req, cancel)
+				shadowc <- resp
+			}()
+
+			sw := &teeWriter{StatusWriter: NewStatusWriter(w)}
+			h.ServeHTTP(sw, r)
+
+			primary := &http.Response{
+				Status:        http.StatusText(sw.Status()),
+				StatusCode:    sw.Status(),
+				Header:        sw.Header().Clone(),
+				Body:          io.NopCloser(&sw.body),
+				ContentLength: int64(sw.body.Len()),
+				Request:       r,
+			}
+
+			go func() {
+				shadow := <-shadowc
+				if shadow == nil {
+					return
+				}
+				defer shadow.Body.Close()
+
+				compare(primary, shadow)
+			}()
+		})
+	}
+}
+
+// shadowRequest clones r for the shadow backend at u
+func shadowRequest(r *http.Request, u *url.URL, body []byte) (*http.Request, context.CancelFunc) {
+	// The shadow request must outlive the primary request
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), shadowTimeout)
+
+	req := r.Clone(ctx)
+	req.RequestURI = ""
+	req.Host = ""
+	req.URL.Scheme = u.Scheme
+	req.URL.Host = u.Host
+	req.URL.Path = strings.TrimSuffix(u.Path, "/") + r.URL.Path
+	req.URL.RawPath = ""
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+
+	return req, cancel
+}
+
+// sendShadow sends the shadow request, buffers the response body and calls
+// cancel afterwards. It returns nil on error.
+func sendShadow(req *http.Request, cancel context.CancelFunc) (*http.Response, error) {
+	defer cancel()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("abutil: shadow: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("abutil: shadow: reading response: %v", err)
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	return resp, nil
+}
@@ -0,0 +1,94 @@
+package abutil
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func shadowMiddlewareContext(t *testing.T, shadowURL string, compare func(primary, shadow *http.Response)) *httptest.ResponseRecorder {
+	u, _ := url.Parse(shadowURL)
+	h := ShadowMiddleware(u, 1<<20, compare)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Primary", "yes")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("primary: " + string(b)))
+	}))
+
+	r := httptest.NewRequest("POST", "/foo?bar=baz", strings.NewReader("body"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestShadowMiddleware(t *testing.T) {
+	type received struct {
+		method, uri, body string
+	}
+	receivedc := make(chan received, 1)
+	comparec := make(chan [2]string, 1)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		receivedc <- received{r.Method, r.RequestURI, string(b)}
+		w.Write([]byte("shadow"))
+	}))
+	defer s.Close()
+
+	w := shadowMiddlewareContext(t, s.URL+"/shadow/", func(primary, shadow *http.Response) {
+		p, _ := io.ReadAll(primary.Body)
+		s, _ := io.ReadAll(shadow.Body)
+		if primary.StatusCode != http.StatusCreated || primary.Header.Get("X-Primary") != "yes" {
+			t.Errorf("Expected the primary response, but got %d %v",
+				primary.StatusCode, primary.Header)
+		}
+		comparec <- [2]string{string(p), string(s)}
+	})
+
+	if w.Code != http.StatusCreated || w.Body.String() != "primary: body" {
+		t.Errorf("Expected the primary response, but got %d %q", w.Code, w.Body)
+	}
+
+	select {
+	case got := <-receivedc:
+		expected := received{"POST", "/shadow/foo?bar=baz", "body"}
+		if got != expected {
+			t.Errorf("Expected the shadow to receive %+v, but got %+v", expected, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the shadow to receive the request")
+	}
+
+	select {
+	case got := <-comparec:
+		if got != [2]string{"primary: body", "shadow"} {
+			t.Errorf("Expected both bodies in compare, but got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected compare to be called")
+	}
+}
+
+func TestShadowMiddlewareError(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	// A failing shadow must not affect the primary response
+	w := shadowMiddlewareContext(t, "http://127.0.0.1:1", func(primary, shadow *http.Response) {
+		t.Error("Expected compare not to be called on shadow errors")
+	})
+
+	if w.Code != http.StatusCreated || w.Body.String() != "primary: body" {
+		t.Errorf("Expected the primary response, but got %d %q", w.Code, w.Body)
+	}
+
+	// Wait for the shadow request to fail
+	time.Sleep(50 * time.Millisecond)
+}
@@ -1,15 +1,22 @@
 package abutil
 
 import (
+	"log"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 )
 
+// reloadHandlers is the number of handlers installed with OnReload
+var reloadHandlers int32
+
 // OnSignal calls the given function on the signals SIGHUP, SIGINT, SIGTERM
-// and SIGQUIT
+// and SIGQUIT. SIGHUP is ignored while a handler installed with OnReload is
+// active, so it reloads instead of stopping.
 func OnSignal(fn func(os.Signal)) {
-	sigc := make(chan os.Signal)
+	sigc := make(chan os.Signal, 1)
 
 	// Listen for signals
 	signal.Notify(sigc,
@@ -21,6 +28,53 @@ func OnSignal(fn func(os.Signal)) {
 	// Call the function on each one
 	for {
 		s := <-sigc
+		if s == syscall.SIGHUP && atomic.LoadInt32(&reloadHandlers) > 0 {
+			continue
+		}
+
 		fn(s)
 	}
 }
+
+// OnReload calls fn on every SIGHUP, e.g. to reload the configuration.
+// Errors and panics of fn are logged. The returned function removes the
+// handler.
+func OnReload(fn func() error) (cancel func()) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	atomic.AddInt32(&reloadHandlers, 1)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sigc:
+				reload(fn)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigc)
+			atomic.AddInt32(&reloadHandlers, -1)
+			close(done)
+		})
+	}
+}
+
+// reload calls fn, logging errors and panics
+func reload(fn func() error) {
+	defer func() {
+		if v := recover(); v != nil {
+			log.Printf("abutil: reload panicked: %v", v)
+		}
+	}()
+
+	if err := fn(); err != nil {
+		log.Printf("abutil: reload failed: %v", err)
+	}
+}
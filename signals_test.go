@@ -1,8 +1,14 @@
 package abutil
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
@@ -12,12 +18,16 @@ func TestOnSignal(t *testing.T) {
 	done := make(chan bool)
 	sg := syscall.SIGHUP
 
+	// OnSignal never returns, so ignore signals sent by later tests
+	var once sync.Once
 	go OnSignal(func(s os.Signal) {
-		if s != sg {
-			t.Errorf("Expected signal %s, but got %s", sg, s)
-		}
+		once.Do(func() {
+			if s != sg {
+				t.Errorf("Expected signal %s, but got %s", sg, s)
+			}
 
-		done <- true
+			done <- true
+		})
 	})
 
 	// Send interrupt after 10ms
@@ -46,3 +56,54 @@ func ExampleOnSignal() {
 
 	// Output: Got signal interrupt
 }
+
+func TestOnReload(t *testing.T) {
+	reloaded := make(chan bool, 1)
+	cancel := OnReload(func() error {
+		reloaded <- true
+		return nil
+	})
+	defer cancel()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("Expected fn to be called on SIGHUP")
+	}
+
+	cancel()
+	cancel()
+
+	if atomic.LoadInt32(&reloadHandlers) != 0 {
+		t.Error("Expected cancel to remove the handler")
+	}
+}
+
+func TestReload(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	called := false
+	reload(func() error {
+		called = true
+		return errors.New("invalid config")
+	})
+
+	if !called {
+		t.Error("Expected fn to be called")
+	}
+
+	if !strings.Contains(buf.String(), "reload failed: invalid config") {
+		t.Errorf("Expected the error to be logged, but got %q", buf.String())
+	}
+
+	buf.Reset()
+	reload(func() error { panic("boom") })
+
+	if !strings.Contains(buf.String(), "reload panicked: boom") {
+		t.Errorf("Expected the panic to be logged, but got %q", buf.String())
+	}
+}
@@ -0,0 +1,62 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedURL(t *testing.T) {
+	secret := []byte("secret")
+	base, _ := url.Parse("https://some.url/downloads/report.pdf?user=42&format=a4")
+	signed := SignURL(base, secret, time.Now().Add(time.Hour))
+
+	if err := VerifySignedURL(httptest.NewRequest("GET", signed, nil), secret); err != nil {
+		t.Errorf("Expected a valid signature, but got %v", err)
+	}
+
+	// The order of the params doesn't matter
+	u, _ := url.Parse(signed)
+	reordered := *u
+	q := strings.Split(u.RawQuery, "&")
+	q[0], q[1] = q[1], q[0]
+	reordered.RawQuery = strings.Join(q, "&")
+	if err := VerifySignedURL(httptest.NewRequest("GET", reordered.String(), nil), secret); err != nil {
+		t.Errorf("Expected a valid signature for reordered params, but got %v", err)
+	}
+
+	tampered := []string{
+		strings.Replace(signed, "report.pdf", "secret.pdf", 1),
+		strings.Replace(signed, "user=42", "user=43", 1),
+		strings.Replace(signed, "exp=", "exp=9", 1),
+		signed + "&admin=1",
+		signed + "&sig=foo",
+		strings.Split(signed, "&sig=")[0],
+	}
+	for _, s := range tampered {
+		if err := VerifySignedURL(httptest.NewRequest("GET", s, nil), secret); err != ErrURLSignature {
+			t.Errorf("Expected %v for %s, but got %v", ErrURLSignature, s, err)
+		}
+	}
+
+	if err := VerifySignedURL(httptest.NewRequest("GET", signed, nil), []byte("other")); err != ErrURLSignature {
+		t.Errorf("Expected %v for another secret, but got %v", ErrURLSignature, err)
+	}
+
+	expired := SignURL(base, secret, time.Now().Add(-time.Second))
+	if err := VerifySignedURL(httptest.NewRequest("GET", expired, nil), secret); err != ErrURLExpired {
+		t.Errorf("Expected %v, but got %v", ErrURLExpired, err)
+	}
+}
+
+func ExampleSignURL() {
+	base, _ := url.Parse("https://some.url/downloads/report.pdf")
+	signed := SignURL(base, []byte("secret"), time.Unix(1456833600, 0))
+
+	fmt.Println(strings.Split(signed, "&sig=")[0])
+
+	// Output: https://some.url/downloads/report.pdf?exp=1456833600
+}
@@ -0,0 +1,61 @@
+package abutil
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SingleFlight dedupes concurrent calls with the same key: while a call is
+// running, others with its key wait for it and share its result. The zero
+// value is ready to use.
+type SingleFlight[T any] struct {
+	m     sync.Mutex
+	calls map[string]*singleFlightCall[T]
+}
+
+type singleFlightCall[T any] struct {
+	wg   sync.WaitGroup
+	v    T
+	err  error
+	dups int
+}
+
+// Do calls fn, unless a call with the same key is running, in which case it
+// waits for that one. It returns the result of fn and if it was shared with
+// other callers. A panic in fn is returned as error to every caller.
+func (s *SingleFlight[T]) Do(key string, fn func() (T, error)) (v T, err error, shared bool) {
+	s.m.Lock()
+	if s.calls == nil {
+		s.calls = make(map[string]*singleFlightCall[T])
+	}
+
+	if c, ok := s.calls[key]; ok {
+		c.dups++
+		s.m.Unlock()
+		c.wg.Wait()
+		return c.v, c.err, true
+	}
+
+	c := &singleFlightCall[T]{}
+	c.wg.Add(1)
+	s.calls[key] = c
+	s.m.Unlock()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				c.err = fmt.Errorf("abutil: singleflight: panic: %v", r)
+			}
+		}()
+
+		c.v, c.err = fn()
+	}()
+
+	s.m.Lock()
+	delete(s.calls, key)
+	shared = c.dups > 0
+	s.m.Unlock()
+	c.wg.Done()
+
+	return c.v, c.err, shared
+}
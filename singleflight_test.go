@@ -0,0 +1,78 @@
+package abutil
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight(t *testing.T) {
+	var s SingleFlight[int]
+
+	v, err, shared := s.Do("foo", func() (int, error) { return 42, nil })
+	if v != 42 || err != nil || shared {
+		t.Errorf("Expected (42, <nil>, false), but got (%d, %v, %t)", v, err, shared)
+	}
+
+	_, err, _ = s.Do("foo", func() (int, error) { return 0, errors.New("fail") })
+	if err == nil || err.Error() != "fail" {
+		t.Errorf("Expected the error of fn, but got %v", err)
+	}
+
+	_, err, _ = s.Do("foo", func() (int, error) { panic("boom") })
+	if err == nil {
+		t.Error("Expected an error for a panic")
+	}
+}
+
+func TestSingleFlightDedup(t *testing.T) {
+	var (
+		s       SingleFlight[int]
+		calls   int32
+		sharedN int32
+		wg      sync.WaitGroup
+	)
+
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, _, shared := s.Do("foo", func() (int, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return 42, nil
+			})
+
+			if v != 42 {
+				t.Errorf("Expected 42, but got %d", v)
+			}
+
+			if shared {
+				atomic.AddInt32(&sharedN, 1)
+			}
+		}()
+	}
+
+	// Wait for all goroutines to join the call
+	for {
+		s.m.Lock()
+		c := s.calls["foo"]
+		joined := c != nil && c.dups == 9
+		s.m.Unlock()
+
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls != 1 || sharedN != 10 {
+		t.Errorf("Expected 1 call shared by 10 callers, but got %d and %d", calls, sharedN)
+	}
+}
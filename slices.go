@@ -0,0 +1,130 @@
+package abutil
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+)
+
+// All returns true if pred returns true for every element of s. It stops at
+// the first element that doesn't match and returns true for an empty slice.
+func All[T any](s []T, pred func(T) bool) bool {
+	for _, v := range s {
+		if !pred(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Any returns true if pred returns true for at least one element of s. It
+// stops at the first match and returns false for an empty slice.
+func Any[T any](s []T, pred func(T) bool) bool {
+	for _, v := range s {
+		if pred(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// None returns true if pred returns false for every element of s. It stops at
+// the first match and returns true for an empty slice.
+func None[T any](s []T, pred func(T) bool) bool {
+	return !Any(s, pred)
+}
+
+// Diff returns the elements only in new as added and the elements only in
+// old as removed, e.g. to compute membership changes. Both keep the order of
+// their first appearance in the respective input, duplicates are returned
+// once.
+func Diff[T comparable](old, new []T) (added, removed []T) {
+	return missingFrom(new, old), missingFrom(old, new)
+}
+
+// missingFrom returns the distinct elements of s that aren't in other
+func missingFrom[T comparable](s, other []T) []T {
+	skip := make(map[T]bool, len(other))
+	for _, v := range other {
+		skip[v] = true
+	}
+
+	var missing []T
+	for _, v := range s {
+		if !skip[v] {
+			missing = append(missing, v)
+			skip[v] = true
+		}
+	}
+
+	return missing
+}
+
+// ShuffleInPlace shuffles s with an unbiased Fisher-Yates shuffle, using
+// crypto/rand as source of randomness
+func ShuffleInPlace[T any](s []T) {
+	ShuffleInPlaceRand(s, cryptoRand())
+}
+
+// ShuffleInPlaceRand is like ShuffleInPlace, but uses the given *rand.Rand,
+// e.g. a seeded one for reproducible tests
+func ShuffleInPlaceRand[T any](s []T, rnd *rand.Rand) {
+	for i := len(s) - 1; i > 0; i-- {
+		j := rnd.Intn(i + 1)
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Sample returns n random elements of s without replacement, using
+// crypto/rand as source of randomness. If n is larger than len(s), all
+// elements are returned shuffled. s isn't modified.
+func Sample[T any](s []T, n int) []T {
+	return SampleRand(s, n, cryptoRand())
+}
+
+// SampleRand is like Sample, but uses the given *rand.Rand
+func SampleRand[T any](s []T, n int, rnd *rand.Rand) []T {
+	if n > len(s) {
+		n = len(s)
+	}
+
+	if n <= 0 {
+		return []T{}
+	}
+
+	c := make([]T, len(s))
+	copy(c, s)
+
+	// Partial Fisher-Yates, only the first n positions are needed
+	for i := 0; i < n; i++ {
+		j := i + rnd.Intn(len(c)-i)
+		c[i], c[j] = c[j], c[i]
+	}
+
+	return c[:n]
+}
+
+// cryptoSource is a rand.Source reading from crypto/rand
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("abutil: crypto/rand failed: " + err.Error())
+	}
+
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+func (cryptoSource) Seed(int64) {}
+
+// cryptoRand returns a *rand.Rand backed by crypto/rand
+func cryptoRand() *rand.Rand {
+	return rand.New(cryptoSource{})
+}
@@ -0,0 +1,176 @@
+package abutil
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestPredicates(t *testing.T) {
+	even := func(i int) bool { return i%2 == 0 }
+
+	cases := []struct {
+		s              []int
+		all, any, none bool
+	}{
+		{nil, true, false, true},
+		{[]int{}, true, false, true},
+		{[]int{2, 4, 6}, true, true, false},
+		{[]int{1, 2, 3}, false, true, false},
+		{[]int{1, 3, 5}, false, false, true},
+	}
+
+	for _, c := range cases {
+		if out := All(c.s, even); out != c.all {
+			t.Errorf("Expected All(%v) to be %v, but got %v", c.s, c.all, out)
+		}
+
+		if out := Any(c.s, even); out != c.any {
+			t.Errorf("Expected Any(%v) to be %v, but got %v", c.s, c.any, out)
+		}
+
+		if out := None(c.s, even); out != c.none {
+			t.Errorf("Expected None(%v) to be %v, but got %v", c.s, c.none, out)
+		}
+	}
+}
+
+func TestPredicatesShortCircuit(t *testing.T) {
+	calls := 0
+	count := func(match bool) func(int) bool {
+		return func(int) bool {
+			calls++
+			return match
+		}
+	}
+
+	s := []int{1, 2, 3}
+	for name, fn := range map[string]func() bool{
+		"All":  func() bool { return All(s, count(false)) },
+		"Any":  func() bool { return Any(s, count(true)) },
+		"None": func() bool { return None(s, count(true)) },
+	} {
+		calls = 0
+		fn()
+		if calls != 1 {
+			t.Errorf("Expected %s to stop after 1 call, but got %d", name, calls)
+		}
+	}
+}
+
+func ExampleAll() {
+	emails := []string{"foo@example.com", "bar@example.com"}
+
+	fmt.Println(All(emails, func(e string) bool {
+		return len(e) > 3
+	}))
+
+	// Output: true
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		old, new, added, removed []string
+	}{
+		// Overlapping
+		{[]string{"a", "b", "c"}, []string{"c", "d", "b", "e"}, []string{"d", "e"}, []string{"a"}},
+		// Disjoint
+		{[]string{"a", "b"}, []string{"c", "d"}, []string{"c", "d"}, []string{"a", "b"}},
+		// Identical
+		{[]string{"a", "b"}, []string{"b", "a"}, nil, nil},
+		// Duplicates
+		{[]string{"a", "a"}, []string{"b", "b", "a"}, []string{"b"}, nil},
+		{nil, []string{"a"}, []string{"a"}, nil},
+		{nil, nil, nil, nil},
+	}
+
+	for _, c := range cases {
+		added, removed := Diff(c.old, c.new)
+		if fmt.Sprint(added) != fmt.Sprint(c.added) || fmt.Sprint(removed) != fmt.Sprint(c.removed) {
+			t.Errorf("Expected (%v, %v) for %v -> %v, but got (%v, %v)", c.added,
+				c.removed, c.old, c.new, added, removed)
+		}
+	}
+}
+
+func ExampleDiff() {
+	added, removed := Diff([]string{"alice", "bob"}, []string{"bob", "carol"})
+	fmt.Println(added, removed)
+
+	// Output: [carol] [alice]
+}
+
+func TestShuffleInPlace(t *testing.T) {
+	s := make([]int, 100)
+	for i := range s {
+		s[i] = i
+	}
+
+	ShuffleInPlace(s)
+
+	sorted := append([]int(nil), s...)
+	sort.Ints(sorted)
+	for i, v := range sorted {
+		if v != i {
+			t.Fatalf("Expected a permutation of 0..99, but got %v", s)
+		}
+	}
+
+	if sort.IntsAreSorted(s) {
+		t.Error("Expected the slice to be shuffled")
+	}
+
+	// Reproducible with a seeded source
+	a, b := []int{1, 2, 3, 4, 5}, []int{1, 2, 3, 4, 5}
+	ShuffleInPlaceRand(a, rand.New(rand.NewSource(42)))
+	ShuffleInPlaceRand(b, rand.New(rand.NewSource(42)))
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("Expected the same order, but got %v and %v", a, b)
+	}
+
+	// Must not panic
+	ShuffleInPlace([]int{})
+}
+
+func TestSample(t *testing.T) {
+	s := []string{"a", "b", "c", "d", "e"}
+	orig := fmt.Sprint(s)
+
+	for _, n := range []int{-1, 0, 3, 5, 10} {
+		out := Sample(s, n)
+
+		expected := n
+		if n > len(s) {
+			expected = len(s)
+		} else if n < 0 {
+			expected = 0
+		}
+
+		if len(out) != expected {
+			t.Errorf("Expected %d elements, but got %d", expected, len(out))
+		}
+
+		seen := map[string]bool{}
+		for _, v := range out {
+			if seen[v] {
+				t.Errorf("Expected distinct elements, but got %v", out)
+			}
+			seen[v] = true
+
+			if !Any(s, func(e string) bool { return e == v }) {
+				t.Errorf("Expected %s to be an element of %v", v, s)
+			}
+		}
+	}
+
+	if fmt.Sprint(s) != orig {
+		t.Errorf("Expected %v not to be modified, but got %v", orig, s)
+	}
+
+	a := SampleRand(s, 3, rand.New(rand.NewSource(1)))
+	b := SampleRand(s, 3, rand.New(rand.NewSource(1)))
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("Expected the same sample, but got %v and %v", a, b)
+	}
+}
@@ -0,0 +1,24 @@
+package abutil
+
+import (
+	"net/http"
+	"time"
+)
+
+// SlowRequestLogger returns a middleware that calls logf (e.g. log.Printf)
+// with method, path, status and duration of every request which took longer
+// than threshold. The other requests aren't logged.
+func SlowRequestLogger(threshold time.Duration, logf func(format string, v ...interface{})) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := NewStatusWriter(w)
+			h.ServeHTTP(sw, r)
+
+			if d := time.Since(start); d > threshold {
+				logf("slow request: %s %s %d %s", r.Method, r.URL.Path,
+					sw.Status(), d)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowRequestLogger(t *testing.T) {
+	var lines []string
+	logf := func(format string, v ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}
+
+	h := SlowRequestLogger(20*time.Millisecond, logf)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				time.Sleep(30 * time.Millisecond)
+				w.WriteHeader(http.StatusAccepted)
+			}
+		}))
+
+	for _, p := range []string{"/fast", "/slow"} {
+		r, _ := http.NewRequest("GET", "http://some.url"+p, nil)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 logged request, but got %d: %v", len(lines), lines)
+	}
+
+	if !strings.HasPrefix(lines[0], "slow request: GET /slow 202 ") {
+		t.Errorf("Unexpected log line %q", lines[0])
+	}
+}
+
+func TestSlowRequestLoggerCombined(t *testing.T) {
+	rec := &fakeMetricsRecorder{}
+	var lines []string
+	logf := func(format string, v ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	}
+
+	// Both middleware share the same StatusWriter
+	h := Metrics(rec, nil)(SlowRequestLogger(0, logf)(http.NotFoundHandler()))
+
+	r, _ := http.NewRequest("GET", "http://some.url/foo", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(lines) != 1 || len(rec.requests) != 1 {
+		t.Fatalf("Expected 1 log line and 1 request, but got %d and %d",
+			len(lines), len(rec.requests))
+	}
+
+	if !strings.Contains(lines[0], " 404 ") || rec.requests[0] != "GET /foo 404" {
+		t.Errorf("Expected both to see 404, but got %q and %q", lines[0],
+			rec.requests[0])
+	}
+}
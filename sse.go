@@ -0,0 +1,116 @@
+package abutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoFlusher is returned by NewSSEWriter if the http.ResponseWriter can't
+// be flushed, which is required for streaming
+var ErrNoFlusher = errors.New("abutil: http.ResponseWriter is no http.Flusher")
+
+// SSEEvent is a single Server-Sent Event. Empty fields are omitted.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+
+	// Retry tells the client how long to wait before reconnecting
+	Retry time.Duration
+}
+
+// SSEWriter writes Server-Sent Events to an http.ResponseWriter, flushing
+// after every event
+type SSEWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewSSEWriter sets the text/event-stream headers and returns a new SSEWriter.
+// It returns ErrNoFlusher if w doesn't support flushing, including wrapped
+// writers (see StatusWriter.Unwrap).
+func NewSSEWriter(w http.ResponseWriter) (*SSEWriter, error) {
+	if !canFlush(w) {
+		return nil, ErrNoFlusher
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+
+	return &SSEWriter{w: w, rc: http.NewResponseController(w)}, nil
+}
+
+// Send sends an event with the given name (may be empty) and data
+func (s *SSEWriter) Send(event, data string) error {
+	return s.SendEvent(SSEEvent{Event: event, Data: data})
+}
+
+// SendJSON sends an event with the given name (may be empty) and v encoded
+// as JSON as data
+func (s *SSEWriter) SendJSON(event string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.Send(event, string(b))
+}
+
+// SendEvent sends the given event. Multi-line data is split into several
+// data fields, as required by the format.
+func (s *SSEWriter) SendEvent(e SSEEvent) error {
+	var b strings.Builder
+
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", sseField(e.ID))
+	}
+
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", sseField(e.Event))
+	}
+
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %s\n",
+			strconv.FormatInt(e.Retry.Milliseconds(), 10))
+	}
+
+	data := strings.ReplaceAll(e.Data, "\r\n", "\n")
+	for _, l := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", l)
+	}
+	b.WriteString("\n")
+
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+// canFlush reports if the innermost of the wrapped http.ResponseWriters is
+// an http.Flusher, wrappers like StatusWriter always are one
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+
+		w = u.Unwrap()
+	}
+
+	_, ok := w.(http.Flusher)
+	return ok
+}
+
+// sseField removes line breaks, which would end the field
+func sseField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
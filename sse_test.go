@@ -0,0 +1,94 @@
+package abutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// noFlushWriter is an http.ResponseWriter without Flush
+type noFlushWriter struct {
+	http.ResponseWriter
+}
+
+func TestSSEWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	s, err := NewSSEWriter(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, but got %s", ct)
+	}
+
+	s.Send("", "Hello")
+	s.Send("greeting", "Hello\nWorld")
+	s.SendJSON("user", map[string]string{"name": "foo"})
+	s.SendEvent(SSEEvent{
+		ID:    "42",
+		Event: "evil\nevent",
+		Data:  "bar",
+		Retry: 3 * time.Second,
+	})
+
+	expected := "data: Hello\n\n" +
+		"event: greeting\ndata: Hello\ndata: World\n\n" +
+		"event: user\ndata: {\"name\":\"foo\"}\n\n" +
+		"id: 42\nevent: evilevent\nretry: 3000\ndata: bar\n\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %q, but got %q", expected, w.Body.String())
+	}
+
+	if !w.Flushed {
+		t.Error("Expected the writer to be flushed")
+	}
+
+	if err := s.SendJSON("", func() {}); err == nil {
+		t.Error("Expected an error for an unencodable value")
+	}
+
+	_, err = NewSSEWriter(noFlushWriter{httptest.NewRecorder()})
+	if err != ErrNoFlusher {
+		t.Errorf("Expected %v, but got %v", ErrNoFlusher, err)
+	}
+
+	// StatusWriter always has Flush, the wrapped writer counts
+	_, err = NewSSEWriter(NewStatusWriter(noFlushWriter{httptest.NewRecorder()}))
+	if err != ErrNoFlusher {
+		t.Errorf("Expected %v behind a StatusWriter, but got %v", ErrNoFlusher, err)
+	}
+
+	if _, err = NewSSEWriter(NewStatusWriter(httptest.NewRecorder())); err != nil {
+		t.Errorf("Expected a flushable StatusWriter to work, but got %v", err)
+	}
+}
+
+func ExampleSSEWriter() {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		s, err := NewSSEWriter(w)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for i := 1; i <= 2; i++ {
+			s.SendJSON("tick", map[string]int{"n": i})
+		}
+	}
+
+	mockRequestContext(nil, func(r *http.Request) {
+		w := httptest.NewRecorder()
+		handler(w, r)
+		fmt.Print(w.Body.String())
+	})
+
+	// Output:
+	// event: tick
+	// data: {"n":1}
+	//
+	// event: tick
+	// data: {"n":2}
+}
@@ -0,0 +1,88 @@
+package abutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// RuntimeStats is the JSON response of StatsHandler
+type RuntimeStats struct {
+	Goroutines int     `json:"goroutines"`
+	GOMAXPROCS int     `json:"gomaxprocs"`
+	NumCPU     int     `json:"num_cpu"`
+	Uptime     string  `json:"uptime"`
+	Memory     MemInfo `json:"memory"`
+	GC         GCInfo  `json:"gc"`
+}
+
+// MemInfo are the memory stats of RuntimeStats in bytes, see
+// runtime.MemStats
+type MemInfo struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+	Sys         uint64 `json:"sys"`
+	HeapAlloc   uint64 `json:"heap_alloc"`
+	HeapSys     uint64 `json:"heap_sys"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapObjects uint64 `json:"heap_objects"`
+}
+
+// GCInfo are the garbage collector stats of RuntimeStats
+type GCInfo struct {
+	NumGC        uint32        `json:"num_gc"`
+	PauseTotal   time.Duration `json:"pause_total_ns"`
+	LastPause    time.Duration `json:"last_pause_ns"`
+	LastGC       *time.Time    `json:"last_gc,omitempty"`
+	NextGCTarget uint64        `json:"next_gc_target"`
+}
+
+// StatsHandler returns a handler responding with the RuntimeStats of the
+// process as JSON, for quick debugging without pprof. Reading the memory
+// stats stops the world very briefly, so it's cheap enough to call often,
+// but expose it on an internal port only.
+func StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+
+		json.NewEncoder(w).Encode(runtimeStats())
+	})
+}
+
+func runtimeStats() RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	s := RuntimeStats{
+		Goroutines: runtime.NumGoroutine(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		Uptime:     UptimeString(),
+		Memory: MemInfo{
+			Alloc:       m.Alloc,
+			TotalAlloc:  m.TotalAlloc,
+			Sys:         m.Sys,
+			HeapAlloc:   m.HeapAlloc,
+			HeapSys:     m.HeapSys,
+			HeapInuse:   m.HeapInuse,
+			HeapObjects: m.HeapObjects,
+		},
+		GC: GCInfo{
+			NumGC:        m.NumGC,
+			PauseTotal:   time.Duration(m.PauseTotalNs),
+			NextGCTarget: m.NextGC,
+		},
+	}
+
+	if m.NumGC > 0 {
+		// PauseNs is a circular buffer of the recent pauses
+		s.GC.LastPause = time.Duration(m.PauseNs[(m.NumGC+255)%256])
+
+		last := time.Unix(0, int64(m.LastGC))
+		s.GC.LastGC = &last
+	}
+
+	return s
+}
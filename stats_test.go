@@ -0,0 +1,50 @@
+package abutil
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestStatsHandler(t *testing.T) {
+	runtime.GC()
+
+	w := httptest.NewRecorder()
+	StatsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/stats", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Expected a JSON content type, but got %s", ct)
+	}
+
+	var stats map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{"goroutines", "gomaxprocs", "num_cpu", "uptime"} {
+		if _, ok := stats[k]; !ok {
+			t.Errorf("Expected the key %s in %v", k, stats)
+		}
+	}
+
+	if stats["goroutines"].(float64) < 1 || int(stats["gomaxprocs"].(float64)) != runtime.GOMAXPROCS(0) {
+		t.Errorf("Unexpected stats %v", stats)
+	}
+
+	mem, _ := stats["memory"].(map[string]interface{})
+	for _, k := range []string{"alloc", "total_alloc", "sys", "heap_alloc",
+		"heap_sys", "heap_inuse", "heap_objects"} {
+		if _, ok := mem[k]; !ok {
+			t.Errorf("Expected the key memory.%s in %v", k, mem)
+		}
+	}
+
+	gc, _ := stats["gc"].(map[string]interface{})
+	for _, k := range []string{"num_gc", "pause_total_ns", "last_pause_ns",
+		"last_gc", "next_gc_target"} {
+		if _, ok := gc[k]; !ok {
+			t.Errorf("Expected the key gc.%s in %v", k, gc)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package abutil
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// StatusWriter wraps an http.ResponseWriter and records the status code the
+// handler responded with and the body size, which is useful for logging and
+// metrics middleware
+type StatusWriter struct {
+	http.ResponseWriter
+
+	// status is the written status code or 0 if none was written yet
+	status int
+
+	// size is the number of body bytes written
+	size int64
+}
+
+// NewStatusWriter wraps the given http.ResponseWriter in a StatusWriter. If
+// w already is a *StatusWriter (e.g. from another middleware), it's returned
+// as is, so middleware can be combined without wrapping twice.
+func NewStatusWriter(w http.ResponseWriter) *StatusWriter {
+	if sw, ok := w.(*StatusWriter); ok {
+		return sw
+	}
+
+	return &StatusWriter{ResponseWriter: w}
+}
+
+// WriteHeader records the status code and calls the underlying WriteHeader.
+// Informational codes (e.g. 103 Early Hints) aren't recorded, as the final
+// status follows them, except for 101 Switching Protocols.
+func (s *StatusWriter) WriteHeader(code int) {
+	if s.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		s.status = code
+	}
+
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Write calls the underlying Write, recording an implicit 200
+func (s *StatusWriter) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+
+	n, err := s.ResponseWriter.Write(b)
+	s.size += int64(n)
+
+	return n, err
+}
+
+// Status returns the written status code. If the handler didn't write
+// anything, it returns 200 as net/http would
+func (s *StatusWriter) Status() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+
+	return s.status
+}
+
+// Written returns true once the status code or body was written, after which
+// the status code can't be changed anymore
+func (s *StatusWriter) Written() bool {
+	return s.status != 0
+}
+
+// Size returns the number of body bytes written
+func (s *StatusWriter) Size() int64 {
+	return s.size
+}
+
+// Flush calls the underlying Flush if the http.ResponseWriter supports it,
+// otherwise it does nothing. Use Unwrap to check if it does.
+func (s *StatusWriter) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		if s.status == 0 {
+			s.status = http.StatusOK
+		}
+
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter, so
+// http.ResponseController can access it
+func (s *StatusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// teeWriter is a StatusWriter that keeps a copy of the body, so middleware
+// can inspect or cache the response after the handler returned
+type teeWriter struct {
+	*StatusWriter
+
+	body bytes.Buffer
+}
+
+func (t *teeWriter) Write(b []byte) (int, error) {
+	n, err := t.StatusWriter.Write(b)
+	t.body.Write(b[:n])
+
+	return n, err
+}
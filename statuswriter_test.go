@@ -0,0 +1,85 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusWriter(t *testing.T) {
+	sw := NewStatusWriter(httptest.NewRecorder())
+	if sw.Status() != http.StatusOK {
+		t.Errorf("Expected default status %d, but got %d", http.StatusOK,
+			sw.Status())
+	}
+
+	if sw.Written() {
+		t.Error("Expected Written to return false")
+	}
+
+	sw.Write(nil)
+	if !sw.Written() {
+		t.Error("Expected Written to return true after Write")
+	}
+
+	rec := httptest.NewRecorder()
+	sw = NewStatusWriter(rec)
+	sw.WriteHeader(http.StatusTeapot)
+	sw.WriteHeader(http.StatusOK)
+	if sw.Status() != http.StatusTeapot {
+		t.Errorf("Expected status %d, but got %d", http.StatusTeapot,
+			sw.Status())
+	}
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("Expected underlying status %d, but got %d",
+			http.StatusTeapot, rec.Code)
+	}
+
+	sw = NewStatusWriter(httptest.NewRecorder())
+	sw.Write([]byte("Foobar"))
+	sw.WriteHeader(http.StatusNotFound)
+	if sw.Status() != http.StatusOK {
+		t.Errorf("Expected status %d, but got %d", http.StatusOK, sw.Status())
+	}
+
+	sw.Write([]byte("Foo"))
+	if sw.Size() != 9 {
+		t.Errorf("Expected size %d, but got %d", 9, sw.Size())
+	}
+
+	// Informational codes aren't the final status, except 101
+	sw = NewStatusWriter(httptest.NewRecorder())
+	sw.WriteHeader(http.StatusEarlyHints)
+	if sw.Written() {
+		t.Error("Expected Written to return false after a 103")
+	}
+
+	sw.WriteHeader(http.StatusCreated)
+	if sw.Status() != http.StatusCreated {
+		t.Errorf("Expected status %d, but got %d", http.StatusCreated,
+			sw.Status())
+	}
+
+	sw = NewStatusWriter(httptest.NewRecorder())
+	sw.WriteHeader(http.StatusSwitchingProtocols)
+	if sw.Status() != http.StatusSwitchingProtocols {
+		t.Errorf("Expected status %d, but got %d",
+			http.StatusSwitchingProtocols, sw.Status())
+	}
+
+	rec = httptest.NewRecorder()
+	sw = NewStatusWriter(rec)
+	sw.Flush()
+	if !rec.Flushed {
+		t.Error("Expected Flush to flush the underlying writer")
+	}
+
+	if sw.Unwrap() != rec {
+		t.Error("Expected Unwrap to return the underlying writer")
+	}
+
+	if NewStatusWriter(sw) != sw {
+		t.Error("Expected NewStatusWriter not to wrap a StatusWriter again")
+	}
+}
@@ -0,0 +1,73 @@
+package abutil
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IrregularPlurals maps singular English words to their plural, if Plural
+// can't derive it by the common rules. Plural reads it without locking, so
+// add words before using it, e.g. in an init function.
+var IrregularPlurals = map[string]string{
+	"child":   "children",
+	"person":  "people",
+	"man":     "men",
+	"woman":   "women",
+	"mouse":   "mice",
+	"goose":   "geese",
+	"foot":    "feet",
+	"tooth":   "teeth",
+	"ox":      "oxen",
+	"sheep":   "sheep",
+	"fish":    "fish",
+	"deer":    "deer",
+	"series":  "series",
+	"species": "species",
+	"news":    "news",
+}
+
+// Pluralize returns singular if count is 1 and plural otherwise. If the
+// chosen form contains a %d, the first one is replaced by count, other
+// verbs and percent signs are kept as they are.
+func Pluralize(count int, singular, plural string) string {
+	s := plural
+	if count == 1 {
+		s = singular
+	}
+
+	return strings.Replace(s, "%d", strconv.Itoa(count), 1)
+}
+
+// Plural returns the plural of an English word by applying the common rules
+// (s, es, ies) or looking it up in IrregularPlurals. It won't be right for
+// every word, but for most.
+func Plural(word string) string {
+	if word == "" {
+		return ""
+	}
+
+	lower := strings.ToLower(word)
+	if p, ok := IrregularPlurals[lower]; ok {
+		// Keep the capitalization of the first letter
+		if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+			pr, size := utf8.DecodeRuneInString(p)
+			return string(unicode.ToUpper(pr)) + p[size:]
+		}
+
+		return p
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"),
+		strings.HasSuffix(lower, "z"), strings.HasSuffix(lower, "ch"),
+		strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	case len(lower) > 1 && strings.HasSuffix(lower, "y") &&
+		!strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return word[:len(word)-1] + "ies"
+	}
+
+	return word + "s"
+}
@@ -0,0 +1,76 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPluralize(t *testing.T) {
+	cases := []struct {
+		count    int
+		expected string
+	}{
+		{0, "0 items"},
+		{1, "1 item"},
+		{2, "2 items"},
+	}
+
+	for _, c := range cases {
+		out := Pluralize(c.count, "%d item", "%d items")
+		if out != c.expected {
+			t.Errorf("Expected %q, but got %q", c.expected, out)
+		}
+	}
+
+	if out := Pluralize(1, "item", "items"); out != "item" {
+		t.Errorf("Expected item, but got %s", out)
+	}
+
+	if out := Pluralize(3, "item", "items"); out != "items" {
+		t.Errorf("Expected items, but got %s", out)
+	}
+
+	// Other percent signs aren't formatting verbs
+	if out := Pluralize(5, "100% of %d item", "100% of %d items"); out != "100% of 5 items" {
+		t.Errorf("Expected 100%% of 5 items, but got %s", out)
+	}
+}
+
+func TestPlural(t *testing.T) {
+	cases := map[string]string{
+		"item":   "items",
+		"bus":    "buses",
+		"box":    "boxes",
+		"match":  "matches",
+		"dish":   "dishes",
+		"city":   "cities",
+		"day":    "days",
+		"child":  "children",
+		"Person": "People",
+		"sheep":  "sheep",
+		"":       "",
+	}
+
+	for in, expected := range cases {
+		if out := Plural(in); out != expected {
+			t.Errorf("Expected Plural(%q) to be %q, but got %q", in, expected, out)
+		}
+	}
+}
+
+func ExamplePluralize() {
+	for _, n := range []int{0, 1, 2} {
+		fmt.Println(Pluralize(n, "%d file was deleted", "%d files were deleted"))
+	}
+
+	// Output:
+	// 0 files were deleted
+	// 1 file was deleted
+	// 2 files were deleted
+}
+
+func ExamplePlural() {
+	fmt.Println(Plural("city"), Plural("child"))
+
+	// Output: cities children
+}
@@ -0,0 +1,213 @@
+package abutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// StructHash returns a deterministic SHA-256 hash (hex) of v, e.g. for
+// caching or change detection. Only exported struct fields are included, by
+// name, so renaming a field changes the hash. Map keys are sorted, pointers
+// and interfaces are followed, nil pointers and interfaces hash as nil and
+// nil slices and maps the same as empty ones. Values implementing
+// encoding.BinaryMarshaler (like time.Time) are hashed by their binary form.
+// Functions, channels and cyclic data result in an error.
+func StructHash(v interface{}) (string, error) {
+	h := &structHasher{seen: map[structVisit]bool{}}
+	if err := h.write(reflect.ValueOf(v)); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(h.buf.Bytes())
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+var binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+
+type structHasher struct {
+	buf  bytes.Buffer
+	seen map[structVisit]bool
+}
+
+// structVisit identifies a pointer, map or slice being hashed. Slices share
+// arrays, so they're only the same if their length is too.
+type structVisit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// enter marks v as being hashed until leave is called, returning an error if
+// it already is, i.e. if it contains itself
+func (h *structHasher) enter(v reflect.Value) (leave func(), err error) {
+	key := structVisit{v.Pointer(), v.Type(), 0}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+
+	if h.seen[key] {
+		return nil, fmt.Errorf("abutil: cyclic value of type %s", v.Type())
+	}
+	h.seen[key] = true
+
+	return func() { delete(h.seen, key) }, nil
+}
+
+func (h *structHasher) uint(n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	h.buf.Write(b[:])
+}
+
+func (h *structHasher) string(s string) {
+	h.uint(uint64(len(s)))
+	h.buf.WriteString(s)
+}
+
+// write encodes v canonically, prefixing every value with its kind
+func (h *structHasher) write(v reflect.Value) error {
+	if !v.IsValid() {
+		h.buf.WriteByte(0)
+		return nil
+	}
+
+	h.buf.WriteByte(byte(v.Kind()))
+
+	if v.Type().Implements(binaryMarshalerType) && v.Kind() != reflect.Ptr &&
+		v.Kind() != reflect.Interface && v.CanInterface() {
+		b, err := v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		h.string(string(b))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.buf.WriteByte(1)
+		} else {
+			h.buf.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.uint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		h.uint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.uint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		h.uint(math.Float64bits(real(v.Complex())))
+		h.uint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		h.string(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Len() > 0 {
+			leave, err := h.enter(v)
+			if err != nil {
+				return err
+			}
+			defer leave()
+		}
+
+		h.uint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := h.write(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+
+			h.string(t.Field(i).Name)
+			if err := h.write(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Len() > 0 {
+			leave, err := h.enter(v)
+			if err != nil {
+				return err
+			}
+			defer leave()
+		}
+
+		return h.writeMap(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			h.buf.WriteByte(0)
+			return nil
+		}
+
+		leave, err := h.enter(v)
+		if err != nil {
+			return err
+		}
+		defer leave()
+
+		h.buf.WriteByte(1)
+		return h.write(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			h.buf.WriteByte(0)
+			return nil
+		}
+
+		h.buf.WriteByte(1)
+		h.string(v.Elem().Type().String())
+		return h.write(v.Elem())
+	default:
+		return fmt.Errorf("abutil: can't hash value of type %s", v.Type())
+	}
+
+	return nil
+}
+
+// writeMap encodes the entries of a map sorted by their encoded key
+func (h *structHasher) writeMap(v reflect.Value) error {
+	type entry struct {
+		key, value []byte
+	}
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		kh := &structHasher{seen: h.seen}
+		if err := kh.write(iter.Key()); err != nil {
+			return err
+		}
+
+		vh := &structHasher{seen: h.seen}
+		if err := vh.write(iter.Value()); err != nil {
+			return err
+		}
+
+		entries = append(entries, entry{kh.buf.Bytes(), vh.buf.Bytes()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	h.uint(uint64(len(entries)))
+	for _, e := range entries {
+		h.buf.Write(e.key)
+		h.buf.Write(e.value)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package abutil
+
+import (
+	"testing"
+	"time"
+)
+
+type structHashInner struct {
+	Tags []string
+}
+
+type structHashTest struct {
+	Name    string
+	Count   int
+	Ratio   float64
+	Labels  map[string]int
+	Inner   structHashInner
+	Ptr     *structHashInner
+	Any     interface{}
+	Created time.Time
+	private string
+}
+
+func newStructHashTest() structHashTest {
+	return structHashTest{
+		Name:    "foo",
+		Count:   42,
+		Ratio:   0.5,
+		Labels:  map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5},
+		Inner:   structHashInner{Tags: []string{"x", "y"}},
+		Ptr:     &structHashInner{Tags: []string{"z"}},
+		Any:     int64(1),
+		Created: time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func structHash(t *testing.T, v interface{}) string {
+	h, err := StructHash(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestStructHash(t *testing.T) {
+	a := newStructHashTest()
+	expected := structHash(t, a)
+
+	if len(expected) != 64 {
+		t.Errorf("Expected a hex SHA-256, but got %s", expected)
+	}
+
+	// Equal values hash equally, regardless of map order
+	for i := 0; i < 10; i++ {
+		b := newStructHashTest()
+		b.private = "ignored"
+		if h := structHash(t, b); h != expected {
+			t.Fatalf("Expected %s for an equal struct, but got %s", expected, h)
+		}
+	}
+
+	changes := map[string]func(*structHashTest){
+		"Name":    func(s *structHashTest) { s.Name = "bar" },
+		"Count":   func(s *structHashTest) { s.Count = 43 },
+		"Labels":  func(s *structHashTest) { s.Labels["a"] = 0 },
+		"Inner":   func(s *structHashTest) { s.Inner.Tags = []string{"xy"} },
+		"Ptr":     func(s *structHashTest) { s.Ptr = nil },
+		"Any":     func(s *structHashTest) { s.Any = int32(1) },
+		"Created": func(s *structHashTest) { s.Created = s.Created.Add(time.Second) },
+	}
+
+	for name, change := range changes {
+		b := newStructHashTest()
+		change(&b)
+		if structHash(t, b) == expected {
+			t.Errorf("Expected changing %s to change the hash", name)
+		}
+	}
+
+	// Nil and empty slices and maps hash the same
+	if structHash(t, structHashInner{}) != structHash(t, structHashInner{Tags: []string{}}) {
+		t.Error("Expected nil and empty slices to hash the same")
+	}
+}
+
+func TestStructHashErrors(t *testing.T) {
+	if _, err := StructHash(struct{ Fn func() }{}); err == nil {
+		t.Error("Expected an error for a func field")
+	}
+
+	type node struct {
+		Next *node
+	}
+	n := &node{}
+	n.Next = n
+	if _, err := StructHash(n); err == nil {
+		t.Error("Expected an error for cyclic data")
+	}
+
+	// Maps and slices containing themselves through interfaces
+	m := map[string]interface{}{}
+	m["self"] = m
+	if _, err := StructHash(m); err == nil {
+		t.Error("Expected an error for a self-referencing map")
+	}
+
+	sl := []interface{}{nil}
+	sl[0] = sl
+	if _, err := StructHash(sl); err == nil {
+		t.Error("Expected an error for a self-referencing slice")
+	}
+
+	// Shared, but acyclic pointers, maps and slices are fine
+	inner := map[string]int{"a": 1}
+	ints := []int{1, 2, 3}
+	if _, err := StructHash([]interface{}{inner, inner, ints, ints[:2]}); err != nil {
+		t.Errorf("Expected no error for shared maps and slices, but got %v", err)
+	}
+
+	shared := &structHashInner{}
+	if _, err := StructHash([]*structHashInner{shared, shared}); err != nil {
+		t.Errorf("Expected no error for shared pointers, but got %v", err)
+	}
+}
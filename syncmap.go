@@ -0,0 +1,85 @@
+package abutil
+
+import (
+	"sync"
+)
+
+// SyncMap is a type-safe map guarded by a sync.RWMutex. The zero value is
+// an empty map ready to use.
+type SyncMap[K comparable, V any] struct {
+	m     sync.RWMutex
+	items map[K]V
+}
+
+// Store sets the value for the key
+func (s *SyncMap[K, V]) Store(key K, value V) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.items == nil {
+		s.items = make(map[K]V)
+	}
+
+	s.items[key] = value
+}
+
+// Load returns the value for the key and if it was present
+func (s *SyncMap[K, V]) Load(key K) (V, bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	v, ok := s.items[key]
+	return v, ok
+}
+
+// LoadOrStore returns the existing value for the key and true, if present.
+// Otherwise it stores the given value and returns it and false.
+func (s *SyncMap[K, V]) LoadOrStore(key K, value V) (V, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if v, ok := s.items[key]; ok {
+		return v, true
+	}
+
+	if s.items == nil {
+		s.items = make(map[K]V)
+	}
+
+	s.items[key] = value
+	return value, false
+}
+
+// Delete removes the key
+func (s *SyncMap[K, V]) Delete(key K) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	delete(s.items, key)
+}
+
+// Range calls fn for each entry until fn returns false. It iterates over a
+// snapshot taken before the first call, so fn may modify the map, but won't
+// see those (or concurrent) changes during the iteration.
+func (s *SyncMap[K, V]) Range(fn func(key K, value V) bool) {
+	s.m.RLock()
+	snapshot := make(map[K]V, len(s.items))
+	for k, v := range s.items {
+		snapshot[k] = v
+	}
+	s.m.RUnlock()
+
+	for k, v := range snapshot {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// Len returns the number of entries
+func (s *SyncMap[K, V]) Len() int {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return len(s.items)
+}
@@ -0,0 +1,88 @@
+package abutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSyncMap(t *testing.T) {
+	var m SyncMap[string, int]
+
+	if _, ok := m.Load("foo"); ok {
+		t.Error("Expected an empty map")
+	}
+
+	m.Store("foo", 1)
+	if v, ok := m.Load("foo"); !ok || v != 1 {
+		t.Errorf("Expected (1, true), but got (%d, %v)", v, ok)
+	}
+
+	if v, loaded := m.LoadOrStore("foo", 2); !loaded || v != 1 {
+		t.Errorf("Expected (1, true), but got (%d, %v)", v, loaded)
+	}
+
+	if v, loaded := m.LoadOrStore("bar", 2); loaded || v != 2 {
+		t.Errorf("Expected (2, false), but got (%d, %v)", v, loaded)
+	}
+
+	if m.Len() != 2 {
+		t.Errorf("Expected length 2, but got %d", m.Len())
+	}
+
+	// Modifying the map during Range must not deadlock
+	count := 0
+	m.Range(func(k string, v int) bool {
+		m.Delete(k)
+		count++
+		return true
+	})
+
+	if count != 2 || m.Len() != 0 {
+		t.Errorf("Expected 2 iterations and an empty map, but got %d and %d",
+			count, m.Len())
+	}
+
+	m.Store("foo", 1)
+	m.Store("bar", 2)
+	count = 0
+	m.Range(func(string, int) bool {
+		count++
+		return false
+	})
+
+	if count != 1 {
+		t.Errorf("Expected Range to stop after 1 iteration, but got %d", count)
+	}
+}
+
+func TestSyncMapConcurrent(t *testing.T) {
+	var m SyncMap[int, int]
+	var stored int32
+
+	Parallel(16, func() {
+		for i := 0; i < 100; i++ {
+			if _, loaded := m.LoadOrStore(i, i); !loaded {
+				atomic.AddInt32(&stored, 1)
+			}
+
+			m.Load(i)
+			m.Range(func(int, int) bool { return true })
+		}
+	})
+
+	if stored != 100 || m.Len() != 100 {
+		t.Errorf("Expected 100 stores and entries, but got %d and %d", stored,
+			m.Len())
+	}
+}
+
+func ExampleSyncMap() {
+	var sessions SyncMap[string, int]
+
+	sessions.Store("foo", 42)
+	v, ok := sessions.Load("foo")
+	fmt.Println(v, ok)
+
+	// Output: 42 true
+}
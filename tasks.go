@@ -0,0 +1,59 @@
+package abutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TaskGroup runs background tasks with a shared context, which is cancelled
+// on Stop. GracefulServer uses one to stop its tasks on shutdown.
+type TaskGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTaskGroup creates a new TaskGroup
+func NewTaskGroup() *TaskGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TaskGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a goroutine. The context passed to fn is cancelled when the
+// group is stopped, fn should return as soon as possible then.
+func (g *TaskGroup) Go(fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		fn(g.ctx)
+	}()
+}
+
+// Stop cancels the context of all tasks and waits for them to return. It
+// returns false if they didn't return within the timeout, a timeout of 0
+// waits forever.
+func (g *TaskGroup) Stop(timeout time.Duration) bool {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	if timeout == 0 {
+		<-done
+		return true
+	}
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-t.C:
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+package abutil
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskGroup(t *testing.T) {
+	g := NewTaskGroup()
+
+	var finished int32
+	for i := 0; i < 3; i++ {
+		g.Go(func(ctx context.Context) {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&finished, 1)
+		})
+	}
+
+	if !g.Stop(time.Second) {
+		t.Error("Expected Stop to return true")
+	}
+
+	if n := atomic.LoadInt32(&finished); n != 3 {
+		t.Errorf("Expected %d finished tasks, but got %d", 3, n)
+	}
+
+	// A timeout of 0 waits forever
+	g = NewTaskGroup()
+	finished = 0
+	g.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&finished, 1)
+	})
+
+	if !g.Stop(0) || atomic.LoadInt32(&finished) != 1 {
+		t.Error("Expected Stop(0) to wait for the task")
+	}
+
+	// Tasks which ignore the context
+	g = NewTaskGroup()
+	g.Go(func(ctx context.Context) {
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	if g.Stop(10 * time.Millisecond) {
+		t.Error("Expected Stop to return false after the timeout")
+	}
+}
+
+func ExampleTaskGroup() {
+	g := NewTaskGroup()
+
+	g.Go(func(ctx context.Context) {
+		t := time.NewTicker(time.Millisecond)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				// Do some periodic work
+			case <-ctx.Done():
+				fmt.Println("Cleaning up")
+				return
+			}
+		}
+	})
+
+	g.Stop(time.Second)
+
+	// Output: Cleaning up
+}
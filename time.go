@@ -0,0 +1,41 @@
+package abutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeLayouts are the layouts ParseTime tries, in order
+var TimeLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// ParseTime parses s using the first matching layout of TimeLayouts.
+// Timestamps without time zone are interpreted as UTC.
+func ParseTime(s string) (time.Time, error) {
+	return ParseTimeLayouts(s, TimeLayouts...)
+}
+
+// ParseTimeLayouts is like ParseTime, but tries the given layouts instead
+func ParseTimeLayouts(s string, layouts ...string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, l := range layouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("abutil: unsupported time format %q", s)
+}
+
+// FormatTime formats t as RFC3339 in UTC
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,54 @@
+package abutil
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	cases := map[string]time.Time{
+		"2015-09-02T13:37:00Z":            time.Date(2015, 9, 2, 13, 37, 0, 0, time.UTC),
+		"2015-09-02T15:37:00+02:00":       time.Date(2015, 9, 2, 13, 37, 0, 0, time.UTC),
+		"2015-09-02T13:37:00.123456789Z":  time.Date(2015, 9, 2, 13, 37, 0, 123456789, time.UTC),
+		"2015-09-02T13:37:00":             time.Date(2015, 9, 2, 13, 37, 0, 0, time.UTC),
+		"2015-09-02 13:37:00":             time.Date(2015, 9, 2, 13, 37, 0, 0, time.UTC),
+		" 2015-09-02 ":                    time.Date(2015, 9, 2, 0, 0, 0, 0, time.UTC),
+		"Wed, 02 Sep 2015 13:37:00 +0000": time.Date(2015, 9, 2, 13, 37, 0, 0, time.UTC),
+		"Wed, 02 Sep 2015 13:37:00 UTC":   time.Date(2015, 9, 2, 13, 37, 0, 0, time.UTC),
+	}
+
+	for in, expected := range cases {
+		out, err := ParseTime(in)
+		if err != nil {
+			t.Errorf("Expected %q to parse, but got %v", in, err)
+			continue
+		}
+
+		if !out.Equal(expected) {
+			t.Errorf("Expected %q to be %s, but got %s", in, expected, out)
+		}
+	}
+
+	if _, err := ParseTime("02.09.2015"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestParseTimeLayouts(t *testing.T) {
+	out, err := ParseTimeLayouts("02.09.2015", "02.01.2006")
+	if err != nil || !out.Equal(time.Date(2015, 9, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2015-09-02, but got %s (%v)", out, err)
+	}
+
+	if _, err := ParseTimeLayouts("2015-09-02", "02.01.2006"); err == nil {
+		t.Error("Expected an error for a layout that wasn't given")
+	}
+}
+
+func ExampleFormatTime() {
+	t, _ := ParseTime("2015-09-02 15:37:00")
+	fmt.Println(FormatTime(t.In(time.FixedZone("CEST", 2*3600))))
+
+	// Output: 2015-09-02T15:37:00Z
+}
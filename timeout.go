@@ -0,0 +1,127 @@
+package abutil
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ContextTimeout returns a middleware that cancels the request context after
+// the given duration, so downstream work (e.g. database queries) observing
+// r.Context().Done() can abort. If the handler hasn't started responding when
+// the deadline is hit, a 503 is written and every later write of the handler
+// is discarded (returning http.ErrHandlerTimeout).
+func ContextTimeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{w: w, h: make(http.Header), ctx: ctx}
+			done := make(chan struct{})
+			panicc := make(chan interface{}, 1)
+
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicc <- p
+					}
+				}()
+
+				h.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicc:
+				panic(p)
+			case <-done:
+				return
+			case <-ctx.Done():
+			}
+
+			tw.mu.Lock()
+			if !tw.wroteHeader {
+				tw.timedOut = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+				tw.mu.Unlock()
+				return
+			}
+			tw.mu.Unlock()
+
+			// The handler already started responding, so we have to wait for
+			// it to finish
+			select {
+			case p := <-panicc:
+				panic(p)
+			case <-done:
+			}
+		})
+	}
+}
+
+// timeoutWriter is the http.ResponseWriter used by ContextTimeout. It buffers
+// the header so the handler can't race with the timeout response.
+type timeoutWriter struct {
+	w   http.ResponseWriter
+	h   http.Header
+	ctx context.Context
+
+	// mu guards timedOut and wroteHeader
+	mu          sync.Mutex
+	timedOut    bool
+	wroteHeader bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expired() || tw.wroteHeader {
+		return
+	}
+
+	tw.writeHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if !tw.wroteHeader {
+		tw.writeHeader(http.StatusOK)
+	}
+
+	return tw.w.Write(b)
+}
+
+// expired reports if the deadline passed before the handler started to
+// respond, the caller must hold mu
+func (tw *timeoutWriter) expired() bool {
+	if !tw.timedOut && !tw.wroteHeader && tw.ctx.Err() != nil {
+		tw.timedOut = true
+	}
+
+	return tw.timedOut
+}
+
+// writeHeader copies the buffered header and writes the status code, the
+// caller must hold mu
+func (tw *timeoutWriter) writeHeader(code int) {
+	dst := tw.w.Header()
+	for k, v := range tw.h {
+		dst[k] = v
+	}
+
+	tw.wroteHeader = true
+	tw.w.WriteHeader(code)
+}
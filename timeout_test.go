@@ -0,0 +1,116 @@
+package abutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContextTimeout(t *testing.T) {
+	mockRequestContext(t, func(r *http.Request) {
+		cancelled := make(chan error, 1)
+		written := make(chan error, 1)
+
+		h := ContextTimeout(10 * time.Millisecond)(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+				cancelled <- r.Context().Err()
+
+				_, err := w.Write([]byte("too late"))
+				written <- err
+			}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if err := <-cancelled; err != context.DeadlineExceeded {
+			t.Errorf("Expected context error %v, but got %v",
+				context.DeadlineExceeded, err)
+		}
+
+		if err := <-written; err != http.ErrHandlerTimeout {
+			t.Errorf("Expected late write to return %v, but got %v",
+				http.ErrHandlerTimeout, err)
+		}
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status %d, but got %d",
+				http.StatusServiceUnavailable, w.Code)
+		}
+
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected an empty body, but got %q", w.Body.String())
+		}
+	})
+
+	mockRequestContext(t, func(r *http.Request) {
+		h := ContextTimeout(time.Second)(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Foo", "bar")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte("Foobar"))
+			}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, but got %d", http.StatusCreated, w.Code)
+		}
+
+		if w.Header().Get("X-Foo") != "bar" {
+			t.Errorf("Expected header X-Foo to be bar, but got %s",
+				w.Header().Get("X-Foo"))
+		}
+
+		if w.Body.String() != "Foobar" {
+			t.Errorf("Expected body Foobar, but got %s", w.Body.String())
+		}
+	})
+
+	mockRequestContext(t, func(r *http.Request) {
+		// The handler responded before the deadline, so it's allowed to finish
+		h := ContextTimeout(10 * time.Millisecond)(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				<-r.Context().Done()
+				w.Write([]byte("done"))
+			}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusAccepted {
+			t.Errorf("Expected status %d, but got %d", http.StatusAccepted, w.Code)
+		}
+
+		if w.Body.String() != "done" {
+			t.Errorf("Expected body done, but got %s", w.Body.String())
+		}
+	})
+}
+
+func ExampleContextTimeout() {
+	slowHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			w.Write([]byte("Finally done"))
+		case <-r.Context().Done():
+			// Abort whatever we were doing, the client already got a 503
+		}
+	})
+
+	h := ContextTimeout(10 * time.Millisecond)(slowHandler)
+
+	mockRequestContext(nil, func(r *http.Request) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		fmt.Println(w.Code)
+	})
+
+	// Output: 503
+}
@@ -0,0 +1,85 @@
+package abutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	cert, err := GenerateSelfSignedCert("localhost", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cert.Leaf.VerifyHostname("localhost"); err != nil {
+		t.Errorf("Expected certificate to be valid for localhost: %v", err)
+	}
+
+	if err := cert.Leaf.VerifyHostname("127.0.0.1"); err != nil {
+		t.Errorf("Expected certificate to be valid for 127.0.0.1: %v", err)
+	}
+
+	if err := cert.Leaf.VerifyHostname("example.com"); err == nil {
+		t.Error("Expected certificate to be invalid for example.com")
+	}
+}
+
+func TestSelfSignedTLSConfig(t *testing.T) {
+	c, err := SelfSignedTLSConfig("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewGracefulServerAddr(l.Addr().String(), http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("Foobar"))
+		}))
+
+	done := make(chan struct{})
+	go func() {
+		s.Serve(tls.NewListener(l, c))
+		close(done)
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(c.Certificates[0].Leaf)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+		Timeout: time.Second,
+	}
+
+	resp, err := client.Get("https://" + l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(b) != "Foobar" {
+		t.Errorf("Expected body Foobar, but got %s", b)
+	}
+
+	if resp.TLS == nil || !resp.TLS.HandshakeComplete {
+		t.Error("Expected a completed TLS handshake")
+	}
+
+	client.CloseIdleConnections()
+	s.Stop(0)
+	<-done
+
+	if !s.Stopped() {
+		t.Error("Stopped returned false after Stop()")
+	}
+}
@@ -0,0 +1,110 @@
+package abutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// traceKey is the context key of the TraceContext
+var traceKey = NewContextKey[TraceContext]("trace")
+
+// TraceContext holds the W3C Trace Context of a request
+type TraceContext struct {
+	// TraceID identifies the whole trace (32 hex characters)
+	TraceID string
+
+	// SpanID identifies the current request (16 hex characters)
+	SpanID string
+
+	// ParentID is the span id of the caller, empty if the trace started here
+	ParentID string
+
+	// Flags are the trace flags, e.g. "01" if sampled
+	Flags string
+}
+
+// Traceparent returns the traceparent header value to pass the trace on to
+// downstream calls, with SpanID as their parent
+func (t TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%s", t.TraceID, t.SpanID, t.Flags)
+}
+
+// Inject sets the traceparent header for a downstream request
+func (t TraceContext) Inject(h http.Header) {
+	h.Set("traceparent", t.Traceparent())
+}
+
+// TraceFromContext returns the TraceContext stored by the Traceparent
+// middleware
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	return traceKey.Value(ctx)
+}
+
+// Traceparent is a middleware that continues the trace of a valid incoming
+// traceparent header or starts a new one with random ids. Either way the
+// request gets a new span id and the TraceContext is stored in the request
+// context (see TraceFromContext).
+func Traceparent(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, ok := ParseTraceparent(r.Header.Get("traceparent"))
+		if ok {
+			t.ParentID = t.SpanID
+		} else {
+			t = TraceContext{TraceID: randomHex(16), Flags: "00"}
+		}
+		t.SpanID = randomHex(8)
+
+		h.ServeHTTP(w, r.WithContext(traceKey.WithValue(r.Context(), t)))
+	})
+}
+
+// ParseTraceparent parses a W3C traceparent header value. The parent id of
+// the header is returned as SpanID.
+func ParseTraceparent(s string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, false
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// Version 00 has exactly four fields, future versions may append more
+	if !isLowerHex(version, 2) || version == "ff" ||
+		(version == "00" && len(parts) != 4) ||
+		!isLowerHex(traceID, 32) || traceID == strings.Repeat("0", 32) ||
+		!isLowerHex(spanID, 16) || spanID == strings.Repeat("0", 16) ||
+		!isLowerHex(flags, 2) {
+		return TraceContext{}, false
+	}
+
+	return TraceContext{TraceID: traceID, SpanID: spanID, Flags: flags}, true
+}
+
+// isLowerHex reports if s consists of n lowercase hex characters
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// randomHex returns n random bytes from crypto/rand, hex encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("abutil: crypto/rand failed: " + err.Error())
+	}
+
+	return hex.EncodeToString(b)
+}
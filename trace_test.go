@@ -0,0 +1,98 @@
+package abutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func traceparentContext(t *testing.T, header string) TraceContext {
+	var tc TraceContext
+	h := Traceparent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ok bool
+		tc, ok = TraceFromContext(r.Context())
+		if !ok {
+			t.Error("Expected a TraceContext in the request context")
+		}
+	}))
+
+	r, _ := http.NewRequest("GET", "http://some.url", nil)
+	if header != "" {
+		r.Header.Set("traceparent", header)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	return tc
+}
+
+func TestTraceparent(t *testing.T) {
+	in := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tc := traceparentContext(t, in)
+
+	if tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the incoming trace id, but got %s", tc.TraceID)
+	}
+
+	if tc.ParentID != "00f067aa0ba902b7" || tc.Flags != "01" {
+		t.Errorf("Expected parent 00f067aa0ba902b7 and flags 01, but got %s "+
+			"and %s", tc.ParentID, tc.Flags)
+	}
+
+	if !isLowerHex(tc.SpanID, 16) || tc.SpanID == tc.ParentID {
+		t.Errorf("Expected a new span id, but got %s", tc.SpanID)
+	}
+
+	h := http.Header{}
+	tc.Inject(h)
+	expected := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + tc.SpanID + "-01"
+	if h.Get("traceparent") != expected {
+		t.Errorf("Expected traceparent %s, but got %s", expected,
+			h.Get("traceparent"))
+	}
+
+	// Invalid or missing headers start a new trace
+	for _, in := range []string{"", "garbage"} {
+		tc = traceparentContext(t, in)
+		if !isLowerHex(tc.TraceID, 32) || !isLowerHex(tc.SpanID, 16) ||
+			tc.ParentID != "" || tc.Flags != "00" {
+			t.Errorf("Expected a new trace for %q, but got %+v", in, tc)
+		}
+	}
+
+	if a, b := traceparentContext(t, ""), traceparentContext(t, ""); a.TraceID == b.TraceID {
+		t.Error("Expected random trace ids")
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	valid := []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		// Future versions may have more fields
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-foo",
+	}
+
+	for _, s := range valid {
+		if _, ok := ParseTraceparent(s); !ok {
+			t.Errorf("Expected %q to be valid", s)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-foo",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+	}
+
+	for _, s := range invalid {
+		if _, ok := ParseTraceparent(s); ok {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package abutil
+
+import (
+	"unsafe"
+)
+
+// UnsafeString returns a string sharing the memory of b, without copying.
+// DANGER: b must not be modified afterwards, as strings are assumed to be
+// immutable. Only use it in hot paths where the allocation matters.
+func UnsafeString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+
+	return unsafe.String(&b[0], len(b))
+}
+
+// UnsafeBytes returns a []byte sharing the memory of s, without copying.
+// DANGER: The result must never be written to, as that would modify the
+// (possibly read-only) memory of s. Only use it in hot paths where the
+// allocation matters.
+func UnsafeBytes(s string) []byte {
+	if s == "" {
+		return nil
+	}
+
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
@@ -0,0 +1,66 @@
+package abutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUnsafeString(t *testing.T) {
+	for _, in := range []string{"", "Foobar", strings.Repeat("x", 1024)} {
+		if out := UnsafeString([]byte(in)); out != in {
+			t.Errorf("Expected %q, but got %q", in, out)
+		}
+	}
+
+	if out := UnsafeString(nil); out != "" {
+		t.Errorf("Expected an empty string, but got %q", out)
+	}
+}
+
+func TestUnsafeBytes(t *testing.T) {
+	for _, in := range []string{"Foobar", strings.Repeat("x", 1024)} {
+		if out := UnsafeBytes(in); !bytes.Equal(out, []byte(in)) {
+			t.Errorf("Expected %q, but got %q", in, out)
+		}
+	}
+
+	if out := UnsafeBytes(""); len(out) != 0 {
+		t.Errorf("Expected an empty slice, but got %q", out)
+	}
+}
+
+var (
+	benchBytes  = bytes.Repeat([]byte("x"), 1024)
+	benchString = strings.Repeat("x", 1024)
+	benchSinkS  string
+	benchSinkB  []byte
+)
+
+func BenchmarkString(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchSinkS = string(benchBytes)
+	}
+}
+
+func BenchmarkUnsafeString(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchSinkS = UnsafeString(benchBytes)
+	}
+}
+
+func BenchmarkBytes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchSinkB = []byte(benchString)
+	}
+}
+
+func BenchmarkUnsafeBytes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchSinkB = UnsafeBytes(benchString)
+	}
+}
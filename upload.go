@@ -0,0 +1,123 @@
+package abutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// ErrFileTooLarge is returned by ReceiveFile if the file exceeds the limit
+var ErrFileTooLarge = errors.New("abutil: file too large")
+
+// maxFormOverhead is how much larger than the file the whole form read by
+// ReceiveFile may be, for the other fields and the multipart boundaries
+const maxFormOverhead = 1 << 20
+
+// ReceiveFile reads the file of the given multipart form field, which may be
+// at most maxBytes large. The content type is sniffed from the content with
+// http.DetectContentType instead of trusting the client. The form is streamed,
+// so no temporary files are created. If the field is missing,
+// http.ErrMissingFile is returned. The whole body may be at most 1 MiB larger
+// than maxBytes, ErrFileTooLarge is returned otherwise.
+func ReceiveFile(r *http.Request, field string, maxBytes int64) (filename string, content []byte, contentType string, err error) {
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, maxBytes+maxFormOverhead)
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return "", nil, "", http.ErrMissingFile
+		}
+		if err != nil {
+			return "", nil, "", formTooLarge(err)
+		}
+
+		if p.FormName() != field || p.FileName() == "" {
+			p.Close()
+			continue
+		}
+		defer p.Close()
+
+		content, err = io.ReadAll(io.LimitReader(p, maxBytes+1))
+		if err != nil {
+			return "", nil, "", formTooLarge(err)
+		}
+
+		if int64(len(content)) > maxBytes {
+			return "", nil, "", ErrFileTooLarge
+		}
+
+		return p.FileName(), content, http.DetectContentType(content), nil
+	}
+}
+
+// formTooLarge returns ErrFileTooLarge if err is caused by the body limit of
+// ReceiveFile, err otherwise
+func formTooLarge(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return ErrFileTooLarge
+	}
+
+	return err
+}
+
+// SaveUpload streams the request body (at most maxBytes) to a file in dir,
+// without buffering it in memory, and returns its path, hex-encoded SHA-256
+// and size. The body is written to a temporary file first, which is renamed
+// to the checksum after it was written completely, so the returned path
+// never refers to a partial upload. On errors (ErrFileTooLarge for larger
+// bodies), the temporary file is removed.
+func SaveUpload(r *http.Request, dir string, maxBytes int64) (path string, sha256sum string, size int64, err error) {
+	if r.Body == nil {
+		return "", "", 0, io.ErrUnexpectedEOF
+	}
+	defer r.Body.Close()
+
+	f, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	h := sha256.New()
+	size, err = io.Copy(f, io.TeeReader(io.LimitReader(r.Body, maxBytes+1), h))
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	if size > maxBytes {
+		return "", "", 0, ErrFileTooLarge
+	}
+
+	if err = f.Sync(); err != nil {
+		return "", "", 0, err
+	}
+
+	if err = f.Close(); err != nil {
+		return "", "", 0, err
+	}
+
+	sha256sum = hex.EncodeToString(h.Sum(nil))
+	path = filepath.Join(dir, sha256sum)
+	if err = os.Rename(f.Name(), path); err != nil {
+		return "", "", 0, err
+	}
+
+	return path, sha256sum, size, nil
+}
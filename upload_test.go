@@ -0,0 +1,106 @@
+package abutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func uploadRequest(t *testing.T, field, filename string, content []byte) *http.Request {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("title", "Some title")
+
+	if field != "" {
+		fw, err := mw.CreateFormFile(field, filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(content)
+	}
+	mw.Close()
+
+	r, _ := http.NewRequest("POST", "http://some.url/upload", &buf)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return r
+}
+
+func TestReceiveFile(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n" + "some image data")
+	r := uploadRequest(t, "avatar", "me.png", png)
+
+	name, content, ct, err := ReceiveFile(r, "avatar", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "me.png" || !bytes.Equal(content, png) || ct != "image/png" {
+		t.Errorf("Expected (me.png, %q, image/png), but got (%s, %q, %s)", png,
+			name, content, ct)
+	}
+
+	// The client's content type isn't trusted
+	r = uploadRequest(t, "avatar", "evil.png", []byte("<html>hi</html>"))
+	_, _, ct, _ = ReceiveFile(r, "avatar", 1024)
+	if ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected a sniffed text/html, but got %s", ct)
+	}
+
+	r = uploadRequest(t, "avatar", "me.png", png)
+	if _, _, _, err := ReceiveFile(r, "avatar", 4); err != ErrFileTooLarge {
+		t.Errorf("Expected %v, but got %v", ErrFileTooLarge, err)
+	}
+
+	// Other parts count towards the limit of the body
+	r = uploadRequest(t, "other", "huge.bin", make([]byte, 2<<20))
+	if _, _, _, err := ReceiveFile(r, "avatar", 1024); err != ErrFileTooLarge {
+		t.Errorf("Expected %v for a huge other part, but got %v", ErrFileTooLarge, err)
+	}
+
+	r = uploadRequest(t, "other", "me.png", png)
+	if _, _, _, err := ReceiveFile(r, "avatar", 1024); err != http.ErrMissingFile {
+		t.Errorf("Expected %v, but got %v", http.ErrMissingFile, err)
+	}
+
+	r, _ = http.NewRequest("POST", "http://some.url/upload", nil)
+	if _, _, _, err := ReceiveFile(r, "avatar", 1024); err == nil {
+		t.Error("Expected an error for a non-multipart request")
+	}
+}
+
+func TestSaveUpload(t *testing.T) {
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("Foobar"), 1000)
+	r, _ := http.NewRequest("PUT", "http://some.url/upload", bytes.NewReader(content))
+
+	path, sum, size, err := SaveUpload(r, dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf("%x", sha256.Sum256(content))
+	if sum != expected || size != int64(len(content)) || path != filepath.Join(dir, expected) {
+		t.Errorf("Expected (%s, %s, %d), but got (%s, %s, %d)",
+			filepath.Join(dir, expected), expected, len(content), path, sum, size)
+	}
+
+	if b, err := os.ReadFile(path); err != nil || !bytes.Equal(b, content) {
+		t.Errorf("Expected the saved file to have the content, but got %v", err)
+	}
+
+	r, _ = http.NewRequest("PUT", "http://some.url/upload", bytes.NewReader(content))
+	if _, _, _, err := SaveUpload(r, dir, 100); err != ErrFileTooLarge {
+		t.Errorf("Expected %v, but got %v", ErrFileTooLarge, err)
+	}
+
+	// The temporary file of the failed upload is removed
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the saved file, but got %d entries", len(entries))
+	}
+}
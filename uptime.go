@@ -0,0 +1,47 @@
+package abutil
+
+import (
+	"fmt"
+	"time"
+)
+
+// startedAt is set when the package is initialized
+var startedAt = time.Now()
+
+// StartedAt returns the time the process started (more precisely, this
+// package was initialized)
+func StartedAt() time.Time {
+	return startedAt
+}
+
+// Uptime returns the time since StartedAt
+func Uptime() time.Duration {
+	return time.Since(startedAt)
+}
+
+// UptimeString returns Uptime in a human-readable way, like "3d 4h 5m 6s"
+func UptimeString() string {
+	return formatUptime(Uptime())
+}
+
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm %ds", days, h, m, s)
+	case h > 0:
+		return fmt.Sprintf("%dh %dm %ds", h, m, s)
+	case m > 0:
+		return fmt.Sprintf("%dm %ds", m, s)
+	}
+
+	return fmt.Sprintf("%ds", s)
+}
@@ -0,0 +1,46 @@
+package abutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUptime(t *testing.T) {
+	start := StartedAt()
+	a := Uptime()
+	time.Sleep(5 * time.Millisecond)
+	b := Uptime()
+
+	if b <= a {
+		t.Errorf("Expected Uptime to increase, but got %s and %s", a, b)
+	}
+
+	if !StartedAt().Equal(start) {
+		t.Errorf("Expected StartedAt to be stable, but got %s and %s", start,
+			StartedAt())
+	}
+
+	if StartedAt().After(time.Now()) {
+		t.Error("Expected StartedAt to be in the past")
+	}
+
+	if UptimeString() == "" {
+		t.Error("Expected a non-empty UptimeString")
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                       "0s",
+		1500 * time.Millisecond: "1s",
+		61 * time.Second:        "1m 1s",
+		time.Hour:               "1h 0m 0s",
+		(3*24+4)*time.Hour + 5*time.Minute + 6*time.Second: "3d 4h 5m 6s",
+	}
+
+	for d, expected := range cases {
+		if out := formatUptime(d); out != expected {
+			t.Errorf("Expected %q for %s, but got %q", expected, d, out)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package abutil
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// defaultPorts are removed by CanonicalURL
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// CanonicalURL normalizes an absolute URL, so different forms of the same
+// URL result in the same string: the scheme and host are lowercased, default
+// ports removed, percent-encodings normalized (unreserved characters decoded,
+// hex digits uppercased), dot segments and duplicate slashes in the path
+// resolved, an empty path becomes "/", the query is sorted by key and the
+// fragment is removed.
+func CanonicalURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return "", errors.New("abutil: URL must be absolute")
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != defaultPorts[scheme] {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	p := normalizePercentEncoding(u.EscapedPath())
+	if p == "" {
+		p = "/"
+	} else {
+		trailing := strings.HasSuffix(p, "/")
+		p = path.Clean(p)
+		if trailing && p != "/" {
+			p += "/"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(scheme)
+	b.WriteString("://")
+	if u.User != nil {
+		b.WriteString(u.User.String())
+		b.WriteByte('@')
+	}
+	b.WriteString(host)
+	b.WriteString(p)
+
+	if u.RawQuery != "" {
+		q, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return "", err
+		}
+
+		// Encode sorts by key
+		if enc := q.Encode(); enc != "" {
+			b.WriteByte('?')
+			b.WriteString(enc)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// URLsEqual reports if a and b have the same CanonicalURL. Invalid URLs are
+// never equal.
+func URLsEqual(a, b string) bool {
+	ca, err := CanonicalURL(a)
+	if err != nil {
+		return false
+	}
+
+	cb, err := CanonicalURL(b)
+	if err != nil {
+		return false
+	}
+
+	return ca == cb
+}
+
+// normalizePercentEncoding decodes percent-encoded unreserved characters and
+// uppercases the hex digits of the others (RFC 3986, section 6.2.2)
+func normalizePercentEncoding(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		c, err := url.PathUnescape(s[i : i+3])
+		if err != nil {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		if isUnreserved(c[0]) {
+			b.WriteByte(c[0])
+		} else {
+			b.WriteString(strings.ToUpper(s[i : i+3]))
+		}
+		i += 2
+	}
+
+	return b.String()
+}
+
+// isUnreserved reports if c is an unreserved URL character
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
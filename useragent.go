@@ -0,0 +1,109 @@
+package abutil
+
+import (
+	"strings"
+)
+
+// BotKeywords are lowercase substrings identifying crawlers, bots and HTTP
+// libraries in user agents. Extend it before handling requests, IsBot reads
+// it without a lock.
+var BotKeywords = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "facebookexternalhit",
+	"mediapartners-google", "bingpreview", "headlesschrome", "lighthouse",
+	"curl/", "wget/", "python-requests", "python-urllib", "go-http-client",
+	"java/", "okhttp", "libwww-perl", "httpclient", "axios/", "node-fetch",
+}
+
+// MobileKeywords are lowercase substrings identifying mobile devices in user
+// agents. Like BotKeywords, it may only be extended while nothing reads it.
+var MobileKeywords = []string{
+	"mobile", "android", "iphone", "ipod", "ipad", "windows phone",
+	"blackberry", "bb10", "opera mini", "kindle", "silk/",
+}
+
+// UserAgentInfo is the best-effort classification of a user agent
+type UserAgentInfo struct {
+	IsBot    bool
+	IsMobile bool
+
+	// OS is e.g. "Windows", "macOS", "iOS", "Android" or "Linux", empty if
+	// unknown
+	OS string
+
+	// Browser is e.g. "Chrome", "Firefox", "Safari" or "Edge", empty if
+	// unknown
+	Browser string
+}
+
+// IsBot reports if the user agent contains one of the BotKeywords. An empty
+// user agent is considered a bot as well.
+func IsBot(ua string) bool {
+	if strings.TrimSpace(ua) == "" {
+		return true
+	}
+
+	return containsAnyKeyword(strings.ToLower(ua), BotKeywords)
+}
+
+// IsMobile reports if the user agent contains one of the MobileKeywords
+func IsMobile(ua string) bool {
+	return containsAnyKeyword(strings.ToLower(ua), MobileKeywords)
+}
+
+// UAInfo classifies the user agent. It's not exhaustive, but covers the major
+// operating systems and browsers.
+func UAInfo(ua string) UserAgentInfo {
+	return UserAgentInfo{
+		IsBot:    IsBot(ua),
+		IsMobile: IsMobile(ua),
+		OS:       firstKeywordMatch(ua, uaOperatingSystems),
+		Browser:  firstKeywordMatch(ua, uaBrowsers),
+	}
+}
+
+// uaKeyword maps user agent substrings to a name. The order matters, since
+// e.g. Chrome's user agent contains Safari as well.
+type uaKeyword struct {
+	substrings []string
+	name       string
+}
+
+var uaOperatingSystems = []uaKeyword{
+	{[]string{"Windows Phone"}, "Windows Phone"},
+	{[]string{"Android"}, "Android"},
+	{[]string{"iPhone", "iPad", "iPod"}, "iOS"},
+	{[]string{"CrOS"}, "ChromeOS"},
+	{[]string{"Mac OS X", "Macintosh"}, "macOS"},
+	{[]string{"Windows"}, "Windows"},
+	{[]string{"Linux"}, "Linux"},
+}
+
+var uaBrowsers = []uaKeyword{
+	{[]string{"Edg/", "EdgA/", "EdgiOS/", "Edge/"}, "Edge"},
+	{[]string{"OPR/", "Opera"}, "Opera"},
+	{[]string{"SamsungBrowser/"}, "Samsung Internet"},
+	{[]string{"Firefox/", "FxiOS/"}, "Firefox"},
+	{[]string{"Chrome/", "CriOS/"}, "Chrome"},
+	{[]string{"Safari/"}, "Safari"},
+	{[]string{"MSIE ", "Trident/"}, "Internet Explorer"},
+}
+
+func containsAnyKeyword(s string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstKeywordMatch(ua string, keywords []uaKeyword) string {
+	for _, k := range keywords {
+		if containsAnyKeyword(ua, k.substrings) {
+			return k.name
+		}
+	}
+
+	return ""
+}
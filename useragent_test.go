@@ -0,0 +1,58 @@
+package abutil
+
+import (
+	"testing"
+)
+
+var userAgents = []struct {
+	ua   string
+	info UserAgentInfo
+}{
+	{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		UserAgentInfo{false, false, "Windows", "Chrome"},
+	},
+	{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+		UserAgentInfo{false, false, "Windows", "Edge"},
+	},
+	{
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
+		UserAgentInfo{false, false, "macOS", "Safari"},
+	},
+	{
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		UserAgentInfo{false, false, "Linux", "Firefox"},
+	},
+	{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+		UserAgentInfo{false, true, "iOS", "Safari"},
+	},
+	{
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+		UserAgentInfo{false, true, "Android", "Chrome"},
+	},
+	{
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		UserAgentInfo{true, false, "", ""},
+	},
+	{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 14_7_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.2 Mobile/15E148 Safari/604.1 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)",
+		UserAgentInfo{true, true, "iOS", "Safari"},
+	},
+	{"curl/8.4.0", UserAgentInfo{true, false, "", ""}},
+	{"", UserAgentInfo{true, false, "", ""}},
+}
+
+func TestUAInfo(t *testing.T) {
+	for _, u := range userAgents {
+		if info := UAInfo(u.ua); info != u.info {
+			t.Errorf("Expected %+v for %q, but got %+v", u.info, u.ua, info)
+		}
+
+		if IsBot(u.ua) != u.info.IsBot || IsMobile(u.ua) != u.info.IsMobile {
+			t.Errorf("Expected IsBot %t and IsMobile %t for %q", u.info.IsBot,
+				u.info.IsMobile, u.ua)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package abutil
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ValidationErrors maps field names to their error messages. It implements
+// error, so it can be returned by validation functions.
+type ValidationErrors map[string][]string
+
+// Add adds an error message for the field
+func (v ValidationErrors) Add(field, msg string) {
+	v[field] = append(v[field], msg)
+}
+
+// Error lists the fields and their messages, sorted by field
+func (v ValidationErrors) Error() string {
+	fields := make([]string, 0, len(v))
+	for f := range v {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	msgs := make([]string, len(fields))
+	for i, f := range fields {
+		msgs[i] = f + " " + strings.Join(v[f], ", ")
+	}
+
+	return "abutil: validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ValidationErrorsFrom converts the errors of ReadJSON, DecodeForm and
+// DecodeBody for values of the wrong type to ValidationErrors, keyed by the
+// field. Other errors (e.g. malformed JSON) return false.
+func ValidationErrorsFrom(err error) (ValidationErrors, bool) {
+	var ve ValidationErrors
+	if errors.As(err, &ve) {
+		return ve, true
+	}
+
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) && ute.Field != "" {
+		return ValidationErrors{ute.Field: {"must be of type " + ute.Type.String()}}, true
+	}
+
+	var fe *formFieldError
+	if errors.As(err, &fe) {
+		return ValidationErrors{fe.field: {"is invalid"}}, true
+	}
+
+	return nil, false
+}
+
+// WriteValidationErrors responds with 422 Unprocessable Entity and the errors
+// as JSON, e.g. {"errors":{"email":["is invalid"]}}
+func WriteValidationErrors(w http.ResponseWriter, errs ValidationErrors) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+
+	json.NewEncoder(w).Encode(struct {
+		Errors ValidationErrors `json:"errors"`
+	}{errs})
+}
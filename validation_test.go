@@ -0,0 +1,69 @@
+package abutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidationErrors(t *testing.T) {
+	errs := ValidationErrors{}
+	errs.Add("email", "is invalid")
+	errs.Add("password", "is too short")
+	errs.Add("password", "must contain a digit")
+
+	w := httptest.NewRecorder()
+	WriteValidationErrors(w, errs)
+
+	expected := `{"errors":{"email":["is invalid"],"password":["is too short","must contain a digit"]}}`
+	if w.Code != http.StatusUnprocessableEntity || strings.TrimSpace(w.Body.String()) != expected {
+		t.Errorf("Expected 422 %s, but got %d %s", expected, w.Code, w.Body)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Expected a JSON content type, but got %s", ct)
+	}
+
+	if errs.Error() != "abutil: validation failed: email is invalid; "+
+		"password is too short, must contain a digit" {
+		t.Errorf("Unexpected error message %q", errs.Error())
+	}
+}
+
+func TestValidationErrorsFrom(t *testing.T) {
+	var dst struct {
+		Age int `json:"age" form:"age"`
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"age": "old"}`))
+	r.Header.Set("Content-Type", "application/json")
+	errs, ok := ValidationErrorsFrom(DecodeBody(r, &dst, 1<<20))
+	if !ok || len(errs) != 1 || fmt.Sprint(errs["age"]) != "[must be of type int]" {
+		t.Errorf("Expected an error for age, but got %v (%t)", errs, ok)
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader("age=old"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	errs, ok = ValidationErrorsFrom(DecodeBody(r, &dst, 1<<20))
+	if !ok || len(errs) != 1 || fmt.Sprint(errs["age"]) != "[is invalid]" {
+		t.Errorf("Expected an error for age, but got %v (%t)", errs, ok)
+	}
+
+	wrapped := fmt.Errorf("validating: %w", ValidationErrors{"name": {"is required"}})
+	if errs, ok := ValidationErrorsFrom(wrapped); !ok || len(errs["name"]) != 1 {
+		t.Errorf("Expected the wrapped ValidationErrors, but got %v (%t)", errs, ok)
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"age": `))
+	r.Header.Set("Content-Type", "application/json")
+	if _, ok := ValidationErrorsFrom(DecodeBody(r, &dst, 1<<20)); ok {
+		t.Error("Expected malformed JSON not to be a validation error")
+	}
+
+	if _, ok := ValidationErrorsFrom(errors.New("foo")); ok {
+		t.Error("Expected other errors not to be validation errors")
+	}
+}
@@ -0,0 +1,76 @@
+package abutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version, Commit and BuildTime describe the running build, as shown by
+// VersionHandler. Set them with -ldflags, e.g.
+// -ldflags "-X github.com/bahlo/abutil.Version=1.2.3", or SetBuildInfo.
+// Commit and BuildTime default to the VCS info embedded by go build.
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// SetBuildInfo sets Version, Commit and BuildTime, for builds that can't use
+// -ldflags. Call it in main before serving VersionHandler.
+func SetBuildInfo(version, commit, buildTime string) {
+	Version = version
+	Commit = commit
+	BuildTime = buildTime
+}
+
+// BuildInfo is the JSON response of VersionHandler
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// VersionHandler returns a handler responding with the BuildInfo of the
+// running build as JSON
+func VersionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		json.NewEncoder(w).Encode(buildInfo())
+	})
+}
+
+// buildInfo returns the BuildInfo, falling back to the info embedded by go
+// build for empty fields
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if info.Version == "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+
+	for _, s := range bi.Settings {
+		switch {
+		case s.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = s.Value
+		case s.Key == "vcs.time" && info.BuildTime == "":
+			info.BuildTime = s.Value
+		}
+	}
+
+	return info
+}
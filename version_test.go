@@ -0,0 +1,37 @@
+package abutil
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestVersionHandler(t *testing.T) {
+	defer SetBuildInfo(Version, Commit, BuildTime)
+	SetBuildInfo("1.2.3", "abc123", "2016-03-01T12:00:00Z")
+
+	w := httptest.NewRecorder()
+	VersionHandler().ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Expected a JSON content type, but got %s", ct)
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"version":    "1.2.3",
+		"commit":     "abc123",
+		"build_time": "2016-03-01T12:00:00Z",
+		"go_version": runtime.Version(),
+	}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Errorf("Expected %s to be %q, but got %q", k, v, fields[k])
+		}
+	}
+}